@@ -0,0 +1,63 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gounit
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/slukits/gounit/pkg/prop"
+)
+
+// checkSeed is the seed of [T.Check]'s property runs; zero means a
+// time based seed is chosen.
+var checkSeed = flag.Int64("gounit.seed", 0,
+	"seed of gounit's T.Check property runs (0 for a random seed)")
+
+// checkN is the number of generated inputs a [T.Check] property is
+// checked against.
+var checkN = flag.Int("gounit.checks", prop.DefaultChecks,
+	"number of generated inputs of gounit's T.Check property runs")
+
+// checkErr default message for failed "Check"-assertion
+const checkErr = "property fails for: %s\n" +
+	"(check %d; shrunk %d times from: %s)%s\n" +
+	"reproduce with: -gounit.seed=%d"
+
+// Check runs given property against values generated by given
+// generators (see [prop.Run]) and fails the test and returns false iff
+// the property doesn't hold; otherwise true is returned.  The property
+// is checked -gounit.checks times (defaulting to [prop.DefaultChecks])
+// with a random seed unless the -gounit.seed flag is set.  A failing
+// input is shrunk to a minimal counterexample which is reported along
+// with the seed reproducing the failure, e.g.:
+//
+//	t.Check(func(a, b int) bool {
+//	    return a+b == b+a
+//	}, prop.Int(-100, 100), prop.Int(-100, 100))
+//
+// If no generators are given they are derived from the property's
+// argument types.  Check fatales the test if given property or
+// generators are not usable.
+func (t T) Check(property interface{}, gg ...prop.Generator) bool {
+	t.t.Helper()
+	f, err := prop.Run(
+		prop.Config{Checks: *checkN, Seed: *checkSeed}, property, gg...)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("gounit: check: %v", err))
+		return false
+	}
+	if f == nil {
+		return true
+	}
+	reason := ""
+	if f.Err != nil {
+		reason = fmt.Sprintf("\n%v", f.Err)
+	}
+	t.Logf("gounit: check: seed: %d: minimal input: %s", f.Seed, f)
+	t.Errorf(assertErr, "check", fmt.Sprintf(checkErr, f,
+		f.Check, f.Shrinks, prop.Format(f.Input), reason, f.Seed))
+	return false
+}
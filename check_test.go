@@ -0,0 +1,61 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gounit_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/prop"
+)
+
+type Check struct{ Suite }
+
+func (s *Check) SetUp(t *T) { t.Parallel() }
+
+func (s *Check) Passes_if_property_holds(t *T) {
+	t.True(t.Check(func(a, b int) bool {
+		return a+b == b+a
+	}, prop.Int(-100, 100), prop.Int(-100, 100)))
+}
+
+func (s *Check) Derives_generators_from_property_arguments(t *T) {
+	t.True(t.Check(func(s string, ii []uint8) bool {
+		return len(s) <= prop.DefaultMaxLen &&
+			len(ii) <= prop.DefaultMaxLen
+	}))
+}
+
+func (s *Check) Reports_seed_and_minimal_input_of_failing_property(
+	t *T,
+) {
+	logs, errs := "", ""
+	t.Mock().Logger(func(i ...interface{}) { logs += fmt.Sprint(i...) })
+	t.Mock().Errorer(func(i ...interface{}) { errs += fmt.Sprint(i...) })
+
+	t.Not.True(t.Check(func(i int) bool { return i < 42 },
+		prop.Int(0, 1000)))
+
+	t.Matched(logs, `gounit: check: seed: -?\d+: minimal input: 42`)
+	t.Contains(errs, "assert check:")
+	t.Contains(errs, "property fails for: 42")
+	t.Matched(errs, `reproduce with: -gounit.seed=-?\d+`)
+}
+
+func (s *Check) Fatales_on_unusable_property(t *T) {
+	failed := false
+	t.Mock().Logger(func(i ...interface{}) {})
+	t.Mock().Canceler(func() { failed = true })
+
+	t.Check(42)
+
+	t.True(failed)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	Run(&Check{}, t)
+}
@@ -10,11 +10,14 @@ import (
 	"github.com/slukits/gounit/cmd/gounit/view"
 )
 
-func viewAbout() *report {
-	return &report{
-		flags: view.RpClearing,
-		ll:    strings.Split(strings.TrimSpace(about), "\n"),
+// viewAbout reports given configuration if not nil followed by the
+// license.
+func viewAbout(cfg *Config) *report {
+	ll := strings.Split(strings.TrimSpace(about), "\n")
+	if cfg != nil {
+		ll = append(append(cfg.lines(), ""), ll...)
 	}
+	return &report{flags: view.RpClearing, ll: ll}
 }
 
 const about = `
@@ -4,7 +4,16 @@
 
 package controller
 
-import "github.com/slukits/gounit/cmd/gounit/view"
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slukits/gounit/cmd/gounit/model"
+	"github.com/slukits/gounit/cmd/gounit/view"
+)
 
 type onMask uint8
 
@@ -13,6 +22,7 @@ const (
 	raceOn
 	statsOn
 	errOn
+	affectedOn
 )
 
 type stater interface {
@@ -21,6 +31,7 @@ type stater interface {
 	removeOneFlag(onMask)
 	suspend()
 	resume()
+	export(io.Writer, string) error
 }
 
 type buttons struct {
@@ -30,10 +41,23 @@ type buttons struct {
 	cls        *buttoner
 	isOn       onMask
 	quitter    func()
+
+	// profiles are switched through by the profile button; profile is
+	// the index of the current profile which is set by profiler.
+	profiles []*model.Profile
+	profile  int
+	profiler func(*model.Profile)
+
+	// config is reported on the about screen and provides the format
+	// of the export button's file which is written to exportDir; a
+	// temporary directory is created on the first export if it is
+	// not set.
+	config    *Config
+	exportDir string
 }
 
 func newButtons(upd func(...interface{})) *buttons {
-	return &buttons{viewUpd: upd}
+	return &buttons{viewUpd: upd, isOn: affectedOn}
 }
 
 func (bb *buttons) defaults() *buttoner {
@@ -53,14 +77,46 @@ func (bb *buttons) defaultListener(label string) {
 	case "help":
 		bb.modelState.suspend()
 		bb.viewUpd(viewHelp(), bb.close())
+	case "export":
+		bb.viewUpd(bb.export())
 	case "quit":
 		bb.quitter()
 	case "about":
 		bb.modelState.suspend()
-		bb.viewUpd(viewAbout(), bb.close())
+		bb.viewUpd(viewAbout(bb.config), bb.close())
 	}
 }
 
+// export writes the test results of the current state to the export
+// file in the export directory replacing a previous export and returns
+// the message bar's report of the export which shows the file's path.
+func (bb *buttons) export() string {
+	format := bb.config.exportFormat()
+	if bb.exportDir == "" {
+		dir, err := os.MkdirTemp("", "gounit-export-")
+		if err != nil {
+			return fmt.Sprintf(exportErr, err)
+		}
+		bb.exportDir = dir
+	}
+	file := filepath.Join(bb.exportDir, ExportFiles[format])
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Sprintf(exportErr, err)
+	}
+	err = bb.modelState.export(f, format)
+	if cErr := f.Close(); err == nil && cErr != nil {
+		err = fmt.Errorf(exportErr, cErr)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf(exportedFmt, file)
+}
+
+// exportedFmt reports in the message bar the written export file.
+const exportedFmt = "exported: %s"
+
 func (bb *buttons) close() *buttoner {
 	if bb.cls == nil {
 		bb.cls = &buttoner{
@@ -73,7 +129,16 @@ func (bb *buttons) close() *buttoner {
 }
 
 func (bb *buttons) switchButtons() *buttoner {
-	return switchButtons(bb.isOn, bb.switchesListener)
+	return switchButtons(bb.isOn, bb.profileLabel(), bb.switchesListener)
+}
+
+// profileLabel returns the label of the profile button; the zero
+// string if there are less than two profiles to switch through.
+func (bb *buttons) profileLabel() string {
+	if len(bb.profiles) < 2 {
+		return ""
+	}
+	return bttProfile + bb.profiles[bb.profile].Name
 }
 
 func (bb *buttons) switchesListener(label string) {
@@ -82,29 +147,46 @@ func (bb *buttons) switchesListener(label string) {
 		bb.viewUpd(bb.defaults())
 	case bttVetOff:
 		bb.isOn |= vetOn
-		bb.viewUpd(switchButtons(bb.isOn, bb.switchesListener))
+		bb.viewUpd(bb.switchButtons())
 		bb.modelState.setOnFlag(vetOn)
 	case bttVetOn:
 		bb.isOn &^= vetOn
-		bb.viewUpd(switchButtons(bb.isOn, bb.switchesListener))
+		bb.viewUpd(bb.switchButtons())
 		bb.modelState.removeOneFlag(vetOn)
 	case bttRaceOff:
 		bb.isOn |= raceOn
-		bb.viewUpd(switchButtons(bb.isOn, bb.switchesListener))
+		bb.viewUpd(bb.switchButtons())
 		// TODO: removing this line we get a wired error report go figure
 		bb.modelState.setOnFlag(raceOn)
 	case bttRaceOn:
 		bb.isOn &^= raceOn
-		bb.viewUpd(switchButtons(bb.isOn, bb.switchesListener))
+		bb.viewUpd(bb.switchButtons())
 		bb.modelState.removeOneFlag(raceOn)
 	case bttStatsOff:
 		bb.isOn |= statsOn
-		bb.viewUpd(switchButtons(bb.isOn, bb.switchesListener))
+		bb.viewUpd(bb.switchButtons())
 		bb.modelState.setOnFlag(statsOn)
 	case bttStatsOn:
 		bb.isOn &^= statsOn
-		bb.viewUpd(switchButtons(bb.isOn, bb.switchesListener))
+		bb.viewUpd(bb.switchButtons())
 		bb.modelState.removeOneFlag(statsOn)
+	case bttAffectedOff:
+		bb.isOn |= affectedOn
+		bb.viewUpd(bb.switchButtons())
+		bb.modelState.setOnFlag(affectedOn)
+	case bttAffectedOn:
+		bb.isOn &^= affectedOn
+		bb.viewUpd(bb.switchButtons())
+		bb.modelState.removeOneFlag(affectedOn)
+	default:
+		if !strings.HasPrefix(label, bttProfile) ||
+			len(bb.profiles) < 2 {
+
+			return
+		}
+		bb.profile = (bb.profile + 1) % len(bb.profiles)
+		bb.viewUpd(bb.switchButtons())
+		bb.profiler(bb.profiles[bb.profile])
 	}
 }
 
@@ -114,6 +196,7 @@ func defaultButtons(l func(string)) *buttoner {
 		listener: l,
 		newBB: []view.ButtonDef{
 			{Label: "switches", Rune: 's'},
+			{Label: "export", Rune: 'e'},
 			{Label: "help", Rune: 'h'},
 			{Label: "about", Rune: 'a'},
 			{Label: "quit", Rune: 'q'},
@@ -128,11 +211,17 @@ const (
 	bttVetOn    = "vet=on"
 	bttStatsOff = "stats=off"
 	bttStatsOn  = "stats=on"
+
+	bttAffectedOff = "affected=off"
+	bttAffectedOn  = "affected=on"
 	// bttCurrentOff = "current=off"
 	// bttCurrentOn  = "current=on"
+
+	// bttProfile prefixes the current profile's name
+	bttProfile = "profile="
 )
 
-func switchButtons(on onMask, l func(string)) *buttoner {
+func switchButtons(on onMask, profile string, l func(string)) *buttoner {
 	bb := &buttoner{
 		replace:  true,
 		listener: l,
@@ -140,6 +229,7 @@ func switchButtons(on onMask, l func(string)) *buttoner {
 			{Label: bttVetOff, Rune: 'v'},
 			{Label: bttRaceOff, Rune: 'r'},
 			{Label: bttStatsOff, Rune: 's'},
+			{Label: bttAffectedOff, Rune: 'a'},
 			// {Label: bttCurrentOff, Rune: 'c'},
 			{Label: "back", Rune: 'b'},
 		},
@@ -153,6 +243,13 @@ func switchButtons(on onMask, l func(string)) *buttoner {
 	if on&statsOn > 0 {
 		bb.newBB[2].Label = bttStatsOn
 	}
+	if on&affectedOn > 0 {
+		bb.newBB[3].Label = bttAffectedOn
+	}
+	if profile != "" {
+		bb.newBB = append(bb.newBB[:4], view.ButtonDef{
+			Label: profile, Rune: 'p'}, bb.newBB[4])
+	}
 	return bb
 }
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/cmd/gounit/model"
 )
 
 type Buttons struct {
@@ -19,8 +20,9 @@ func (s *Buttons) fx(t *T) *Testing {
 }
 
 var switchBttFX = []string{
-	"[v]et=off", "[r]ace=off", "[s]tats=off", "[b]ack"}
-var dfltBttFX = []string{"[s]witches", "[h]elp", "[a]bout", "[q]uit"}
+	"[v]et=off", "[r]ace=off", "[s]tats=off", "[a]ffected=on", "[b]ack"}
+var dfltBttFX = []string{"[s]witches", "[e]xport", "[h]elp", "[a]bout",
+	"[q]uit"}
 
 func (s *Buttons) Init(t *S) { initGolden(t) }
 
@@ -119,6 +121,55 @@ func (s *Buttons) Switches_stats_button(t *T) {
 	t.Contains(tt.ButtonBarCells(), vw2)
 }
 
+func (s *Buttons) Switches_affected_button(t *T) {
+	tt := s.fx(t)
+	tt.ClickButton("switches")
+	t.SpaceMatched(tt.ButtonBarCells(), switchBttFX...)
+	t.True(tt._controller.model.isAffectedOn())
+
+	label, vw := tt.switchButtonLabel("affected")
+	t.Contains(tt.ButtonBarCells(), vw)
+
+	tt.ClickButton(label)
+	label, vw2 := tt.switchButtonLabel("affected")
+	t.FatalIfNot(t.Not.Eq(vw, vw2))
+	t.Contains(tt.ButtonBarCells(), vw2)
+	t.Not.True(tt._controller.model.isAffectedOn())
+
+	tt.ClickButton(label)
+	_, vw2 = tt.switchButtonLabel("affected")
+	t.FatalIfNot(t.Eq(vw, vw2))
+	t.Contains(tt.ButtonBarCells(), vw2)
+	t.True(tt._controller.model.isAffectedOn())
+}
+
+func (s *Buttons) Switches_through_configured_profiles(t *T) {
+	mck := &watcherMock{}
+	unit := &model.Profile{Name: "unit", Short: true}
+	integration := &model.Profile{Name: "integration",
+		Tags: []string{"integration"}}
+	tt := fxInit(t, InitFactories{Watcher: mck,
+		Profiles: []*model.Profile{unit, integration}}, nil)
+	t.True(mck.lastProfile() == unit)
+	tt.ClickButton("switches")
+	t.SpaceMatched(tt.ButtonBarCells(), "[v]et=off", "[r]ace=off",
+		"[s]tats=off", "[a]ffected=on", "[p]rofile=unit", "[b]ack")
+
+	tt.ClickButton("profile=unit")
+	t.Contains(tt.ButtonBarCells(), "[p]rofile=integration")
+	t.True(mck.lastProfile() == integration)
+
+	tt.ClickButton("profile=integration")
+	t.Contains(tt.ButtonBarCells(), "[p]rofile=unit")
+	t.True(mck.lastProfile() == unit)
+}
+
+func (s *Buttons) Hides_profile_button_without_profiles(t *T) {
+	tt := s.fx(t)
+	tt.ClickButton("switches")
+	t.Not.Contains(tt.ButtonBarCells(), "profile")
+}
+
 func TestButtons(t *testing.T) {
 	t.Parallel()
 	Run(&Buttons{}, t)
@@ -0,0 +1,323 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/slukits/gounit/cmd/gounit/model"
+)
+
+// ConfigFiles are the names of gounit's configuration files in the
+// order they are looked up in a directory, see [LoadConfig].
+var ConfigFiles = []string{".gounit.toml", ".gounit.json"}
+
+const (
+	configErr = "gounit: config: %s: %v"
+)
+
+// A Config holds the settings of the watcher, the test runs and the
+// initial switches of the user interface.  Its zero value represents
+// gounit's defaults.  A configuration file's keys are the lower cased
+// field names, e.g. a .gounit.toml:
+//
+//	timeout = "2m"
+//	ignore = ["vendor"]
+//	race = true
+//	args = ["-failfast"]
+//
+//	[[profiles]]
+//	name = "unit"
+//	short = true
+//
+//	[[profiles]]
+//	name = "integration"
+//	tags = ["integration"]
+//	env = ["DB=localhost:5432"]
+//
+// or the equivalent .gounit.json:
+//
+//	{
+//	    "timeout": "2m",
+//	    "ignore": ["vendor"],
+//	    "race": true,
+//	    "args": ["-failfast"],
+//	    "profiles": [
+//	        {"name": "unit", "short": true},
+//	        {"name": "integration", "tags": ["integration"],
+//	            "env": ["DB=localhost:5432"]}
+//	    ]
+//	}
+type Config struct {
+
+	// File is the path of the loaded configuration file; the zero
+	// string if no configuration file was found.
+	File string `json:"-"`
+
+	// Dir is the watched directory which defaults to the working
+	// directory.  It is not read from a configuration file.
+	Dir string `json:"-"`
+
+	// Interval is the duration between two checks for changes;
+	// defaults to model.DefaultInterval.
+	Interval Duration `json:"interval"`
+
+	// Timeout cancels a package's test run; defaults to
+	// model.DefaultTimeout.
+	Timeout Duration `json:"timeout"`
+
+	// Ignore are directory names which are ignored in addition to
+	// model.DefaultIgnore in the search for testing packages.
+	Ignore []string `json:"ignore"`
+
+	// Inputs are glob patterns of package inputs, see
+	// model.Sources.Inputs.
+	Inputs []string `json:"inputs"`
+
+	// Workers limits concurrent test runs, see InitFactories.Workers.
+	Workers int `json:"workers"`
+
+	// Vet, Race and Stats set the initial state of their switches.
+	Vet   bool `json:"vet"`
+	Race  bool `json:"race"`
+	Stats bool `json:"stats"`
+
+	// Affected sets the initial state of the affected switch which is
+	// on unless set to false.
+	Affected *bool `json:"affected"`
+
+	// Args are go test flags and Env environment variables of the
+	// form "KEY=value" which are added to each test run, i.e. to each
+	// profile.
+	Args []string `json:"args"`
+	Env  []string `json:"env"`
+
+	// Profiles are the run profiles which can be switched through in
+	// the user interface, see InitFactories.Profiles.
+	Profiles []*model.Profile `json:"profiles"`
+
+	// Export is the format of the file the export button writes into
+	// a temporary directory, i.e. "json" or "junit"; defaults to
+	// "json", see ExportFiles.
+	Export string `json:"export"`
+}
+
+// Duration is a time.Duration which is configured by a string like
+// "1m30s", see time.ParseDuration.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(bb []byte) error {
+	var s string
+	if err := json.Unmarshal(bb, &s); err != nil {
+		return fmt.Errorf("duration: expected string like \"30s\"")
+	}
+	_d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(_d)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadConfig reads the first configuration file found ascending from
+// given directory to the root of its go module, see [ConfigFiles].  A
+// zero Config is returned if no configuration file is found.  An error
+// is returned if a found configuration file can't be read or decoded,
+// e.g. because of an unknown key.
+func LoadConfig(dir string) (*Config, error) {
+	file := findConfig(dir)
+	if file == "" {
+		return &Config{}, nil
+	}
+	bb, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf(configErr, file, err)
+	}
+	if filepath.Ext(file) == ".toml" {
+		doc, err := decodeTOML(bb)
+		if err != nil {
+			return nil, fmt.Errorf(configErr, file, err)
+		}
+		if bb, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf(configErr, file, err)
+		}
+	}
+	cfg := &Config{}
+	dec := json.NewDecoder(bytes.NewReader(bb))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			err = fmt.Errorf("line %d: %v",
+				bytes.Count(bb[:syntaxErr.Offset], []byte("\n"))+1, err)
+		}
+		return nil, fmt.Errorf(configErr, file, err)
+	}
+	if _, ok := ExportFiles[cfg.Export]; cfg.Export != "" && !ok {
+		return nil, fmt.Errorf(configErr, file, unknownFormat(cfg.Export))
+	}
+	cfg.File = file
+	return cfg, nil
+}
+
+// findConfig returns the first configuration file found ascending from
+// given directory to the first directory containing a go.mod file; the
+// zero string if there is none.
+func findConfig(dir string) string {
+	for {
+		for _, n := range ConfigFiles {
+			if fi, err := os.Stat(filepath.Join(dir, n)); err == nil &&
+				!fi.IsDir() {
+
+				return filepath.Join(dir, n)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return ""
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// watcher returns the default watcher configured by receiving
+// configuration.
+func (c *Config) watcher() *model.Sources {
+	s := &model.Sources{
+		Dir:      c.Dir,
+		Interval: time.Duration(c.Interval),
+		Timeout:  time.Duration(c.Timeout),
+		Inputs:   c.Inputs,
+	}
+	if len(c.Ignore) > 0 {
+		s.Ignore = append(append([]string{}, model.DefaultIgnore...),
+			c.Ignore...)
+	}
+	return s
+}
+
+// profiles returns the configured profiles whereas the configured args
+// and environment are added to each profile.  A default profile is
+// returned if args or environment are configured without profiles.
+func (c *Config) profiles() []*model.Profile {
+	if len(c.Args) == 0 && len(c.Env) == 0 {
+		return c.Profiles
+	}
+	pp := c.Profiles
+	if len(pp) == 0 {
+		pp = []*model.Profile{{Name: model.DefaultProfile.Name}}
+	}
+	_pp := make([]*model.Profile, len(pp))
+	for i, p := range pp {
+		_p := *p
+		_p.Args = append(append([]string{}, c.Args...), p.Args...)
+		_p.Env = append(append([]string{}, c.Env...), p.Env...)
+		_pp[i] = &_p
+	}
+	return _pp
+}
+
+// onMask returns the initially switched on switches.
+func (c *Config) onMask() (m onMask) {
+	if c.Vet {
+		m |= vetOn
+	}
+	if c.Race {
+		m |= raceOn
+	}
+	if c.Stats {
+		m |= statsOn
+	}
+	if c.Affected == nil || *c.Affected {
+		m |= affectedOn
+	}
+	return m
+}
+
+// exportFormat returns the configured export format defaulting to
+// ExportJSON.
+func (c *Config) exportFormat() string {
+	if c == nil || c.Export == "" {
+		return ExportJSON
+	}
+	return c.Export
+}
+
+// lines reports receiving configuration's settings for the about
+// screen.
+func (c *Config) lines() []string {
+	file := c.File
+	if file == "" {
+		file = "none (defaults)"
+	}
+	interval := time.Duration(c.Interval)
+	timeout := time.Duration(c.Timeout)
+	if interval == 0 {
+		interval = model.DefaultInterval
+	}
+	if timeout == 0 {
+		timeout = model.DefaultTimeout
+	}
+	switches := []string{}
+	for _, s := range []struct {
+		name string
+		on   onMask
+	}{{"vet", vetOn}, {"race", raceOn}, {"stats", statsOn},
+		{"affected", affectedOn}} {
+		if c.onMask()&s.on == 0 {
+			continue
+		}
+		switches = append(switches, s.name)
+	}
+	profiles := []string{}
+	for _, p := range c.Profiles {
+		profiles = append(profiles, p.Name)
+	}
+	ignore := c.watcher().Ignore
+	if len(ignore) == 0 {
+		ignore = model.DefaultIgnore
+	}
+	ll := []string{
+		"configuration: " + file,
+		"    interval: " + interval.String(),
+		"    timeout: " + timeout.String(),
+		"    ignore: " + strings.Join(ignore, ", "),
+	}
+	optional := []struct {
+		label string
+		vv    []string
+	}{{"inputs", c.Inputs}, {"switches", switches}, {"args", c.Args},
+		{"env", c.Env}, {"profiles", profiles}}
+	for _, o := range optional {
+		if len(o.vv) == 0 {
+			continue
+		}
+		ll = append(ll, fmt.Sprintf("    %s: %s", o.label,
+			strings.Join(o.vv, ", ")))
+	}
+	if c.Workers > 0 {
+		ll = append(ll, fmt.Sprintf("    workers: %d", c.Workers))
+	}
+	if c.Export != "" {
+		ll = append(ll, "    export: "+c.Export)
+	}
+	if c.Dir != "" {
+		ll = append(ll, "    dir: "+c.Dir)
+	}
+	return ll
+}
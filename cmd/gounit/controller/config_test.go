@@ -0,0 +1,250 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/cmd/gounit/model"
+)
+
+type ConfigFile struct{ Suite }
+
+func (s *ConfigFile) SetUp(t *T) { t.Parallel() }
+
+const fxTOMLConfig = `
+# gounit configuration
+interval = "1s"
+timeout = '2m' # literal string
+ignore = [
+    "vendor", # vendored packages
+    "tools",
+]
+inputs = ["*.sql"]
+workers = 2
+vet = true
+affected = false
+args = ["-failfast"]
+env = ["DB=localhost:5432"]
+
+[[profiles]]
+name = "unit"
+short = true
+
+[[profiles]]
+name = "integration"
+tags = ["integration"]
+count = 1
+`
+
+const fxJSONConfig = `{
+	"interval": "1s", "timeout": "2m", "ignore": ["vendor", "tools"],
+	"inputs": ["*.sql"], "workers": 2, "vet": true, "affected": false,
+	"args": ["-failfast"], "env": ["DB=localhost:5432"],
+	"profiles": [
+		{"name": "unit", "short": true},
+		{"name": "integration", "tags": ["integration"], "count": 1}
+	]
+}`
+
+// assertFxConfig asserts that given configuration has the settings of
+// the configuration fixtures.
+func assertFxConfig(t *T, cfg *Config) {
+	t.Eq(time.Second, time.Duration(cfg.Interval))
+	t.Eq(2*time.Minute, time.Duration(cfg.Timeout))
+	t.Eq([]string{"vendor", "tools"}, cfg.Ignore)
+	t.Eq([]string{"*.sql"}, cfg.Inputs)
+	t.Eq(2, cfg.Workers)
+	t.Eq(vetOn, cfg.onMask())
+	t.FatalIfNot(t.Eq(2, len(cfg.Profiles)))
+	t.True(cfg.Profiles[0].Short)
+	t.Eq([]string{"integration"}, cfg.Profiles[1].Tags)
+	t.Eq(1, cfg.Profiles[1].Count)
+}
+
+func (s *ConfigFile) Defaults_to_zero_config_if_not_found(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+
+	cfg, err := LoadConfig(dir.Path())
+	t.FatalOn(err)
+
+	t.Eq("", cfg.File)
+	t.Eq(affectedOn, cfg.onMask())
+	t.Eq(0, len(cfg.profiles()))
+}
+
+func (s *ConfigFile) Is_decoded_from_toml(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.toml", []byte(fxTOMLConfig))
+
+	cfg, err := LoadConfig(dir.Path())
+	t.FatalOn(err)
+
+	t.True(strings.HasSuffix(cfg.File, ".gounit.toml"))
+	assertFxConfig(t, cfg)
+}
+
+func (s *ConfigFile) Is_decoded_from_json(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.json", []byte(fxJSONConfig))
+
+	cfg, err := LoadConfig(dir.Path())
+	t.FatalOn(err)
+
+	t.True(strings.HasSuffix(cfg.File, ".gounit.json"))
+	assertFxConfig(t, cfg)
+}
+
+func (s *ConfigFile) Is_discovered_ascending_to_module_root(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.toml", []byte("race = true\n"))
+	nested, _ := dir.Mk("a", "b")
+
+	cfg, err := LoadConfig(nested.Path())
+	t.FatalOn(err)
+
+	t.Eq(raceOn|affectedOn, cfg.onMask())
+}
+
+func (s *ConfigFile) Is_not_discovered_beyond_module_root(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkFile(".gounit.toml", []byte("race = true\n"))
+	module, _ := dir.Mk("module")
+	module.MkMod("example.com/config")
+
+	cfg, err := LoadConfig(module.Path())
+	t.FatalOn(err)
+
+	t.Eq("", cfg.File)
+}
+
+func (s *ConfigFile) Fails_on_unknown_keys(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.toml", []byte("raec = true\n"))
+
+	_, err := LoadConfig(dir.Path())
+	t.ErrMatched(err, "raec")
+}
+
+func (s *ConfigFile) Fails_on_unknown_export_format(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.toml", []byte("export = \"xml\"\n"))
+
+	_, err := LoadConfig(dir.Path())
+	t.ErrMatched(err, "unknown format")
+}
+
+func (s *ConfigFile) Fails_reporting_the_line_of_a_syntax_error(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.toml", []byte("vet = true\nrace = \"on\n"))
+
+	_, err := LoadConfig(dir.Path())
+	t.ErrMatched(err, "line 2")
+}
+
+func (s *ConfigFile) Fails_reporting_the_line_of_a_json_syntax_error(
+	t *T,
+) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.json", []byte("{\"vet\": true,\n\"race\": on}"))
+
+	_, err := LoadConfig(dir.Path())
+	t.ErrMatched(err, "line 2")
+}
+
+func (s *ConfigFile) Is_looked_up_as_toml_before_json(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.json", []byte(`{"vet": true}`))
+	dir.MkFile(".gounit.toml", []byte("race = true\n"))
+
+	cfg, err := LoadConfig(dir.Path())
+	t.FatalOn(err)
+
+	t.True(strings.HasSuffix(cfg.File, ".gounit.toml"))
+	t.Eq(raceOn|affectedOn, cfg.onMask())
+}
+
+func (s *ConfigFile) Decodes_toml_integers_and_strings(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/config")
+	dir.MkFile(".gounit.toml", []byte("workers = 1_0\n"+
+		`args = ["a\tb", "\u00e9\"", 'C:\new']`+"\n"+
+		`"env" = ["A=0x10"]`+"\n"))
+
+	cfg, err := LoadConfig(dir.Path())
+	t.FatalOn(err)
+
+	t.Eq(10, cfg.Workers)
+	t.Eq([]string{"a\tb", "\u00e9\"", `C:\new`}, cfg.Args)
+	t.Eq([]string{"A=0x10"}, cfg.Env)
+}
+
+func (s *ConfigFile) Fails_on_invalid_toml(t *T) {
+	for _, invalid := range []string{
+		"workers = 010", "workers = 1__0", "workers = _1",
+		"workers = 1_", "workers = 0x", "workers = 1.",
+		"vet = True", `args = ["\x41"]`, `args = ["\a"]`,
+		`args = ["\u00"]`, `args = ["a` + "\x01" + `"]`,
+		"workers = 1 2", "vet = true\nvet = false",
+		"a.b = 1", "env = { a = 1 }",
+	} {
+		dir := t.FS().Tmp()
+		dir.MkMod("example.com/config")
+		dir.MkFile(".gounit.toml", []byte(invalid+"\n"))
+
+		_, err := LoadConfig(dir.Path())
+		t.ErrMatched(err, "toml: line [12]:")
+	}
+}
+
+func (s *ConfigFile) Adds_args_and_env_to_each_profile(t *T) {
+	cfg := &Config{Args: []string{"-failfast"}, Env: []string{"A=1"},
+		Profiles: []*model.Profile{{Name: "a", Args: []string{"-v"}}}}
+
+	pp := cfg.profiles()
+
+	t.Eq([]string{"-failfast", "-v"}, pp[0].Args)
+	t.Eq([]string{"A=1"}, pp[0].Env)
+	t.Eq([]string{"-v"}, cfg.Profiles[0].Args)
+	t.Eq(model.DefaultProfile.Name, (&Config{Env: []string{"A=1"}}).
+		profiles()[0].Name)
+}
+
+func (s *ConfigFile) Sets_initial_switches(t *T) {
+	tt := fxInit(t, InitFactories{Config: &Config{Vet: true,
+		Stats: true}}, nil)
+	tt.ClickButton("switches")
+
+	t.SpaceMatched(tt.ButtonBarCells(), "[v]et=on", "[r]ace=off",
+		"[s]tats=on", "[a]ffected=on", "[b]ack")
+	t.True(tt._controller.model.isAffectedOn())
+}
+
+func (s *ConfigFile) Is_shown_on_about_screen(t *T) {
+	tt := fxInit(t, InitFactories{Config: &Config{
+		File: "/module/.gounit.toml", Race: true}}, nil)
+
+	tt.ClickButtons("about")
+
+	t.Contains(tt.ReportCells(), "configuration: /module/.gounit.toml")
+	t.Contains(tt.ReportCells(), "switches: race, affected")
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Parallel()
+	Run(&ConfigFile{}, t)
+}
@@ -46,9 +46,28 @@ type InitFactories struct {
 	// Fatal to report fatal errors; defaults to log.Fatal
 	Fatal func(...interface{})
 
-	// Watcher wraps a controller's model; defaults to &model.Sources{}
+	// Watcher wraps a controller's model; defaults to a
+	// &model.Sources{} configured by Config.
 	Watcher Watcher
 
+	// Config provides the default watcher, workers and profiles as well
+	// as the initial switches; defaults to the zero Config.  It is
+	// reported on the about screen.
+	Config *Config
+
+	// Workers limits the number of concurrent test runs of packages;
+	// defaults to Config.Workers respectively GOMAXPROCS.  Queued test
+	// runs of failing packages and then of most recently modified
+	// packages are started first.
+	Workers int
+
+	// Profiles are the run profiles which can be switched through in
+	// the switches bar.  The first profile is the initial profile of
+	// the watched sources; defaults to the profiles of Config
+	// respectively to model.DefaultProfile.  The profile button is only
+	// shown for at least two profiles.
+	Profiles []*model.Profile
+
 	// watch waits concurrently for a watcher to report watched testing
 	// packages and updates accordingly the view; defaults to a
 	// controller internal function and is there for testing
@@ -85,6 +104,9 @@ func New(i *InitFactories) {
 		i = &InitFactories{}
 	}
 	ensureInitArgs(i)
+	if len(i.Profiles) > 0 {
+		i.Watcher.SetProfile(i.Profiles[0])
+	}
 	diff, _, err := i.Watcher.Watch()
 	if err != nil {
 		i.Fatal(fmt.Sprintf(
@@ -105,8 +127,17 @@ func ensureInitArgs(i *InitFactories) {
 	if i.Fatal == nil {
 		i.Fatal = log.Fatal
 	}
+	if i.Config == nil {
+		i.Config = &Config{}
+	}
 	if i.Watcher == nil {
-		i.Watcher = &model.Sources{}
+		i.Watcher = i.Config.watcher()
+	}
+	if i.Workers == 0 {
+		i.Workers = i.Config.Workers
+	}
+	if len(i.Profiles) == 0 {
+		i.Profiles = i.Config.profiles()
 	}
 	if i.View == nil {
 		i.View = func(i view.Initer) lines.Componenter {
@@ -129,11 +160,19 @@ func ensureInitArgs(i *InitFactories) {
 					&report{
 						ll:    []string{initReport},
 						flags: view.RpClearing}},
-				pp: pkgs{}},
+				pp:   pkgs{},
+				isOn: i.Config.onMask()},
 		},
 		watcher: i.Watcher,
 		ftl:     i.Fatal,
 	}
 	i.controller.model.viewUpdater = i.controller.view.Update
+	i.controller.model.pool = newPool(
+		i.Workers, i.controller.model.updateQueued)
+	i.controller.model.generations = newGenerations()
 	i.controller.bb = newButtons(i.controller.view.Update)
+	i.controller.bb.isOn = i.Config.onMask()
+	i.controller.bb.config = i.Config
+	i.controller.bb.profiles = i.Profiles
+	i.controller.bb.profiler = i.Watcher.SetProfile
 }
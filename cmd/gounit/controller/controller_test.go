@@ -5,12 +5,16 @@
 package controller
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
 	. "github.com/slukits/gounit"
 	"github.com/slukits/gounit/cmd/gounit/model"
+	"github.com/slukits/gounit/pkg/testjson"
 )
 
 // Gounit tests the behavior of Controller.New which is identical with
@@ -45,7 +49,8 @@ func (s *Gounit) fx(t *T) *Testing {
 }
 
 func (s *Gounit) Shows_initially_default_buttons(t *T) {
-	exp := []string{"[s]witches", "[h]elp", "[a]bout", "[q]uit"}
+	exp := []string{"[s]witches", "[e]xport", "[h]elp", "[a]bout",
+		"[q]uit"}
 	tt := s.fx(t)
 
 	t.SpaceMatched(tt.ButtonBarCells(), exp...)
@@ -81,7 +86,7 @@ func (s *Gounit) Shows_about_screen(t *T) {
 	tt := s.fx(t)
 	tt.ClickButtons("about")
 	got := tt.splitTrimmed(tt.ReportCells().Trimmed().String())
-	t.SpaceMatched(about, got...)
+	t.SpaceMatched(strings.Join((&Config{}).lines(), "\n")+about, got...)
 }
 
 func (s *Gounit) Shows_last_report_going_back_from_about(t *T) {
@@ -194,9 +199,7 @@ func (s *Gounit) Locks_selected_suite_on_test_file_updated(t *T) {
 	tt.ClickReporting(3) // select suite 1
 	t.FatalIfNot(t.Contains(tt.ReportCells(), "suite 1"))
 
-	tt.before(func() {
-		tt.golden.Touch("twosuites/pass_test.go")
-	})
+	tt.before(func() { fxEdit(tt.golden, "twosuites/pass_test.go") })
 	t.Contains(tt.ReportCells(), "suite 1")
 }
 
@@ -206,9 +209,7 @@ func (s *Gounit) Locks_selected_go_tests_on_test_file_update(t *T) {
 	tt.ClickReporting(2) // select go-tests
 	t.FatalIfNot(t.Contains(tt.ReportCells(), "go-tests"))
 
-	tt.before(func() {
-		tt.golden.Touch("twosuites/pass_test.go")
-	})
+	tt.before(func() { fxEdit(tt.golden, "twosuites/pass_test.go") })
 	t.Contains(tt.ReportCells(), "go-tests")
 }
 
@@ -219,9 +220,7 @@ func (s *Gounit) Locks_selected_go_suite_on_test_file_update(t *T) {
 	tt.ClickReporting(8) // select go-suite
 	t.FatalIfNot(t.Contains(tt.ReportCells(), "p4 sub 3"))
 
-	tt.before(func() {
-		tt.golden.Touch("twosuites/pass_test.go")
-	})
+	tt.before(func() { fxEdit(tt.golden, "twosuites/pass_test.go") })
 	t.Contains(tt.ReportCells(), "p4 sub 3")
 }
 
@@ -269,7 +268,9 @@ func (s *Gounit) Suspends_model_change_reporting_showing_something_else(
 	tt.ClickButtons("about")
 	t.FatalIfNot(t.Contains(tt.ReportCells(), "gounit Copyright"))
 
-	tt.beforeWatch(func() { tt.golden.Touch("mixed/pp/pkg3") })
+	tt.beforeWatch(func() {
+		fxEdit(tt.golden, "mixed/pp/pkg3/pass_test.go")
+	})
 	t.Contains(tt.ReportCells(), "gounit Copyright")
 }
 
@@ -319,3 +320,109 @@ func TestGounit(t *testing.T) {
 	t.Parallel()
 	Run(&Gounit{}, t)
 }
+
+type RunProgress struct{ Suite }
+
+func (s *RunProgress) SetUp(t *T) { t.Parallel() }
+
+func (s *RunProgress) Shows_most_recently_started_running_test(t *T) {
+	msg := ""
+	mdl := &modelState{Mutex: &sync.Mutex{}, viewUpdater: func(
+		dd ...interface{},
+	) {
+		msg = dd[0].(string)
+	}}
+	progress := mdl.progress("pkg")
+
+	progress(&model.Progress{Test: "TestA", Action: testjson.Run})
+	t.Eq(fmt.Sprintf(progressFmt, "pkg", "TestA", 0, 0), msg)
+	progress(&model.Progress{Test: "TestB", Action: testjson.Run})
+	t.Eq(fmt.Sprintf(progressFmt, "pkg", "TestB", 0, 0), msg)
+	progress(&model.Progress{
+		Test: "TestB", Action: testjson.Fail, Failed: 1})
+	t.Eq(fmt.Sprintf(progressFmt, "pkg", "TestA", 0, 1), msg)
+
+	mdl.suspend()
+	progress(&model.Progress{Test: "TestC", Action: testjson.Run})
+	t.Eq(fmt.Sprintf(progressFmt, "pkg", "TestA", 0, 1), msg)
+}
+
+func TestRunProgress(t *testing.T) {
+	t.Parallel()
+	Run(&RunProgress{}, t)
+}
+
+type Generations struct{ Suite }
+
+func (s *Generations) SetUp(t *T) { t.Parallel() }
+
+func (s *Generations) supersede(gg *generations, ID string, n int) {
+	gg.Lock()
+	defer gg.Unlock()
+	gg.supersede(ID, n)
+}
+
+func (s *Generations) Cancel_superseded_runs(t *T) {
+	gg := newGenerations()
+	s.supersede(gg, "pkg", 1)
+	ctx := gg.start("pkg", 1)
+	t.True(ctx.Err() == nil)
+
+	s.supersede(gg, "pkg", 2)
+
+	t.ErrIs(ctx.Err(), context.Canceled)
+	t.ErrIs(gg.start("pkg", 1).Err(), context.Canceled)
+	t.True(gg.start("pkg", 2).Err() == nil)
+}
+
+func (s *Generations) Report_only_the_latest_generation(t *T) {
+	gg := newGenerations()
+	s.supersede(gg, "pkg", 1)
+	s.supersede(gg, "other", 1)
+	s.supersede(gg, "pkg", 2)
+
+	t.Not.True(gg.isLatest("pkg", 1))
+	t.True(gg.isLatest("pkg", 2))
+	t.True(gg.isLatest("other", 1))
+}
+
+func (s *Generations) Dont_update_state_if_superseded(t *T) {
+	gg, updated := newGenerations(), false
+	mdl := &modelState{Mutex: &sync.Mutex{}, state: &state{pp: pkgs{}},
+		viewUpdater: func(...interface{}) { updated = true }}
+	s.supersede(gg, "pkg", 2)
+
+	(&generation{n: 1, deleted: []string{"pkg"}}).apply(gg, mdl)
+
+	t.Not.True(updated)
+}
+
+func (s *Generations) Dont_update_state_without_packages(t *T) {
+	gg, updated := newGenerations(), false
+	mdl := &modelState{Mutex: &sync.Mutex{}, state: &state{pp: pkgs{}},
+		viewUpdater: func(...interface{}) { updated = true }}
+
+	t.True((&generation{n: 1}).apply(gg, mdl) == nil)
+
+	t.Not.True(updated)
+}
+
+func (s *Generations) Cancel_reruns_of_reported_packages(t *T) {
+	gg := newGenerations()
+	ctx, cancel := gg.current("pkg")
+	defer cancel()
+	other, cancelOther := gg.current("other")
+	defer cancelOther()
+
+	s.supersede(gg, "pkg", 1)
+
+	t.ErrIs(ctx.Err(), context.Canceled)
+	t.True(other.Err() == nil)
+	gg.cancelAll()
+	t.ErrIs(other.Err(), context.Canceled)
+}
+
+func TestGenerations(t *testing.T) {
+	t.Parallel()
+	Run(&Generations{}, t)
+}
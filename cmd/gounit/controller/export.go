@@ -0,0 +1,349 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+export.go exports the test results of testing packages in the structure
+gounit reports them: packages ordered by their IDs, a package's go tests
+in the order of the go-tests report followed by its suites in their
+written order, a suite's tests and (nested) sub-tests.  Each test has
+besides its name its human readable title, its duration, its output and
+in case of a failure the location of the failure.  The export is
+written as JSON whose durations are numbers of seconds or as JUnit XML
+which is understood by most CI dashboards.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slukits/gounit/cmd/gounit/model"
+)
+
+// Export formats of the export button and of [Export].
+const (
+	ExportJSON  = "json"
+	ExportJUnit = "junit"
+)
+
+// ExportFiles maps the export formats to the name of the file the
+// export button writes to a temporary directory; i.e. the watched
+// directory isn't changed by an export.
+var ExportFiles = map[string]string{
+	ExportJSON:  "gounit-report.json",
+	ExportJUnit: "gounit-report.xml",
+}
+
+const (
+	exportErr = "gounit: export: %v"
+)
+
+// exportReport is the exported state of all testing packages.
+type exportReport struct {
+	Passed   bool             `json:"passed"`
+	Packages []*exportPackage `json:"packages"`
+}
+
+type exportPackage struct {
+	Package  string         `json:"package"`
+	Passed   bool           `json:"passed"`
+	Duration exportSeconds  `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+	Tests    []*exportTest  `json:"tests,omitempty"`
+	Suites   []*exportSuite `json:"suites,omitempty"`
+}
+
+type exportTest struct {
+	Name     string        `json:"name"`
+	Title    string        `json:"title"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Flaky    bool          `json:"flaky,omitempty"`
+	Duration exportSeconds `json:"duration"`
+	Location string        `json:"location,omitempty"`
+	Output   []string      `json:"output,omitempty"`
+	Tests    []*exportTest `json:"tests,omitempty"`
+}
+
+type exportSuite struct {
+	exportTest
+	Runner         string   `json:"runner"`
+	InitOutput     []string `json:"initOutput,omitempty"`
+	FinalizeOutput []string `json:"finalizeOutput,omitempty"`
+}
+
+// exportSeconds is a duration which is exported as number of seconds.
+type exportSeconds time.Duration
+
+func (s exportSeconds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(s).Seconds())
+}
+
+func (s *exportSeconds) UnmarshalJSON(bb []byte) error {
+	var f float64
+	if err := json.Unmarshal(bb, &f); err != nil {
+		return err
+	}
+	*s = exportSeconds(f * float64(time.Second))
+	return nil
+}
+
+// writeExport writes the export of given packages in given format to
+// given writer.
+func writeExport(w io.Writer, pp pkgs, format string) error {
+	r := newExport(pp)
+	switch format {
+	case ExportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf(exportErr, err)
+		}
+		return nil
+	case ExportJUnit:
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return fmt.Errorf(exportErr, err)
+		}
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(newJUnit(r)); err != nil {
+			return fmt.Errorf(exportErr, err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return fmt.Errorf(exportErr, err)
+		}
+		return nil
+	}
+	return fmt.Errorf(exportErr, unknownFormat(format))
+}
+
+// unknownFormat returns the error of an unknown export format.
+func unknownFormat(format string) error {
+	return fmt.Errorf("unknown format %q; expected %q or %q",
+		format, ExportJSON, ExportJUnit)
+}
+
+func newExport(pp pkgs) *exportReport {
+	ids := []string{}
+	for id := range pp {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	r := &exportReport{Passed: true}
+	for _, id := range ids {
+		ep := exportPkg(pp[id])
+		if !ep.Passed {
+			r.Passed = false
+		}
+		r.Packages = append(r.Packages, ep)
+	}
+	return r
+}
+
+func exportPkg(p *pkg) *exportPackage {
+	ep := &exportPackage{Package: p.ID()}
+	if p.runResult == nil {
+		ep.Error = "not run"
+		return ep
+	}
+	if p.Results == nil {
+		ep.Error = p.err.Error()
+		return ep
+	}
+	ep.Duration = exportSeconds(p.Duration)
+	if p.HasErr() {
+		ep.Error = p.Err()
+		return ep
+	}
+	ep.Passed = p.Passed()
+	_, _, without, with := goSplitTests(p)
+	for _, t := range append(without, with...) {
+		if tr := p.OfTest(t); tr != nil {
+			ep.Tests = append(ep.Tests,
+				exportResult(p, t.Name(), tr.Result))
+		}
+	}
+	p.ForSuite(func(s *model.TestSuite) {
+		rr := p.OfSuite(s)
+		if rr == nil {
+			return
+		}
+		es := &exportSuite{
+			exportTest:     *exportResult(p, s.Name(), rr.Result),
+			Runner:         s.Runner(),
+			InitOutput:     rr.InitOut,
+			FinalizeOutput: rr.FinalizeOut,
+		}
+		// suite tests are exported in their written order
+		es.Tests = nil
+		s.ForTest(func(t *model.Test) {
+			if sr := rr.Of(t.Name()); sr != nil {
+				es.Tests = append(es.Tests,
+					exportResult(p, t.Name(), sr.Result))
+			}
+		})
+		ep.Suites = append(ep.Suites, es)
+	})
+	return ep
+}
+
+// exportResult exports given result of the test with given name
+// including its ordered sub-tests.
+func exportResult(p *pkg, name string, r *model.Result) *exportTest {
+	et := &exportTest{
+		Name:    name,
+		Title:   model.HumanReadable(name),
+		Passed:  r.Passed,
+		Skipped: r.Skipped,
+		Flaky:   r.Flaky,
+		Output:  r.Output,
+	}
+	if !r.Start.IsZero() && r.End.After(r.Start) {
+		et.Duration = exportSeconds(r.End.Sub(r.Start))
+	}
+	if !r.Passed {
+		et.Location = failureLocation(p, r.Output)
+	}
+	r.ForOrdered(func(sr *model.SubResult) {
+		et.Tests = append(et.Tests,
+			exportResult(p, sr.Name, sr.Result))
+	})
+	return et
+}
+
+// failureLocation returns the first location in given output which
+// points into given package's files; the zero string if there is none.
+func failureLocation(p *pkg, out []string) string {
+	for _, s := range out {
+		if loc, _, ok := pkgFileLoc(p, s); ok {
+			return strings.TrimSuffix(loc, ":")
+		}
+	}
+	return ""
+}
+
+// junitSuites is the JUnit XML representation of an export.
+type junitSuites struct {
+	XMLName  xml.Name      `xml:"testsuites"`
+	Tests    int           `xml:"tests,attr"`
+	Failures int           `xml:"failures,attr"`
+	Errors   int           `xml:"errors,attr"`
+	Suites   []*junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string       `xml:"name,attr"`
+	Tests     int          `xml:"tests,attr"`
+	Failures  int          `xml:"failures,attr"`
+	Errors    int          `xml:"errors,attr"`
+	Skipped   int          `xml:"skipped,attr"`
+	Time      string       `xml:"time,attr"`
+	Cases     []*junitCase `xml:"testcase"`
+	SystemOut string       `xml:"system-out,omitempty"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// newJUnit maps given export to JUnit test suites: a package's go
+// tests are the test suite named by the package's ID and each suite is
+// a test suite named by the package's ID and the suite's title.  Only
+// tests without sub-tests are test cases whose names are the titles
+// of their ancestors and their own joined by "/".
+func newJUnit(r *exportReport) *junitSuites {
+	jj := &junitSuites{}
+	add := func(js *junitSuite) {
+		jj.Tests += js.Tests
+		jj.Failures += js.Failures
+		jj.Errors += js.Errors
+		jj.Suites = append(jj.Suites, js)
+	}
+	for _, p := range r.Packages {
+		if p.Error != "" {
+			jc := &junitCase{Name: p.Package, Classname: p.Package,
+				Time: seconds(p.Duration), Error: &junitMessage{
+					Message: "test run failed", Text: p.Error}}
+			add(&junitSuite{Name: p.Package, Tests: 1, Errors: 1,
+				Time: jc.Time, Cases: []*junitCase{jc}})
+			continue
+		}
+		if len(p.Tests) > 0 {
+			js := &junitSuite{Name: p.Package}
+			var d exportSeconds
+			for _, t := range p.Tests {
+				junitCases(js, p.Package, "", t)
+				d += t.Duration
+			}
+			js.Time = seconds(d)
+			add(js)
+		}
+		for _, s := range p.Suites {
+			js := &junitSuite{Name: p.Package + "/" + s.Title,
+				Time: seconds(s.Duration)}
+			for _, t := range s.Tests {
+				junitCases(js, js.Name, "", t)
+			}
+			js.SystemOut = strings.Join(append(append([]string{},
+				s.InitOutput...), s.FinalizeOutput...), "\n")
+			add(js)
+		}
+	}
+	return jj
+}
+
+// junitCases adds the test cases of given test and its sub-tests to
+// given JUnit suite whereas the case names are prefixed by given
+// prefix.
+func junitCases(js *junitSuite, class, prefix string, t *exportTest) {
+	name := prefix + t.Title
+	if len(t.Tests) > 0 {
+		for _, st := range t.Tests {
+			junitCases(js, class, name+"/", st)
+		}
+		return
+	}
+	jc := &junitCase{Name: name, Classname: class,
+		Time: seconds(t.Duration)}
+	out := strings.Join(t.Output, "\n")
+	js.Tests++
+	switch {
+	case t.Skipped:
+		js.Skipped++
+		jc.Skipped = &junitMessage{Text: out}
+	case !t.Passed:
+		js.Failures++
+		msg := "failed"
+		if t.Location != "" {
+			msg = "failed at " + t.Location
+		}
+		jc.Failure = &junitMessage{Message: msg, Text: out}
+	default:
+		jc.SystemOut = out
+	}
+	js.Cases = append(js.Cases, jc)
+}
+
+// seconds formats given duration as seconds with millisecond
+// precision.
+func seconds(d exportSeconds) string {
+	return fmt.Sprintf("%.3f", time.Duration(d).Seconds())
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/cmd/gounit/model"
+)
+
+type Exporting struct{ Suite }
+
+func (s *Exporting) SetUp(t *T) { t.Parallel() }
+
+func (s *Exporting) Json_with_packages_tests_and_failure_location(t *T) {
+	out := &bytes.Buffer{}
+
+	passed, err := Export(&InitFactories{Watcher: fxHeadless(t, true)},
+		out, ExportJSON)
+	t.FatalOn(err)
+	t.Not.True(passed)
+
+	r := &exportReport{}
+	t.FatalOn(json.Unmarshal(out.Bytes(), r))
+	t.Not.True(r.Passed)
+	t.FatalIfNot(t.Eq(2, len(r.Packages)))
+	fail, pass := r.Packages[0], r.Packages[1]
+	t.Eq("fail", fail.Package)
+	t.Eq("pass", pass.Package)
+	t.True(pass.Passed)
+	t.FatalIfNot(t.Eq(1, len(fail.Tests)))
+	t.Eq("TestFail", fail.Tests[0].Name)
+	t.Eq("fail", fail.Tests[0].Title)
+	t.Eq("./fail_test.go:5", fail.Tests[0].Location)
+	t.Contains(fmt.Sprint(fail.Tests[0].Output), "failed")
+}
+
+func (s *Exporting) Junit_with_failures_as_failing_test_cases(t *T) {
+	out := &bytes.Buffer{}
+
+	_, err := Export(&InitFactories{Watcher: fxHeadless(t, true)},
+		out, ExportJUnit)
+	t.FatalOn(err)
+
+	jj := &junitSuites{}
+	t.FatalOn(xml.Unmarshal(out.Bytes(), jj))
+	t.Eq(2, jj.Tests)
+	t.Eq(1, jj.Failures)
+	t.FatalIfNot(t.Eq(2, len(jj.Suites)))
+	t.Eq("fail", jj.Suites[0].Name)
+	t.FatalIfNot(t.Eq(1, len(jj.Suites[0].Cases)))
+	failure := jj.Suites[0].Cases[0].Failure
+	t.FatalIfNot(t.True(failure != nil))
+	t.Eq("failed at ./fail_test.go:5", failure.Message)
+	t.Contains(failure.Text, "failed")
+}
+
+// fxGoldenExport returns the watcher of a temporary module with a copy
+// of the golden module's export package.  The module's gounit
+// dependency is replaced by this repository, i.e. its tests run
+// offline.
+func fxGoldenExport(t *T) *model.Sources {
+	td, _ := t.FS().Data()
+	repo := td.Child(filepath.Join("..", "..", "..", ".."))
+	mod := t.FS().Tmp()
+	td.Child(filepath.Join(goldenDir, "export")).Copy(mod)
+	mod.MkFile("go.mod", []byte(strings.Replace(
+		string(repo.FileContent("go.mod")),
+		"module github.com/slukits/gounit", "module example.com/export", 1)))
+	mod.MkFile("go.sum", repo.FileContent("go.sum"))
+	mod.MkRequire("github.com/slukits/gounit", "v0.0.0")
+	mod.MkModReplace("github.com/slukits/gounit", repo)
+	return &model.Sources{Dir: mod.Path(), Interval: time.Millisecond}
+}
+
+// exportNames returns the space separated names of given tests.
+func exportNames(tt []*exportTest) string {
+	nn := []string{}
+	for _, t := range tt {
+		nn = append(nn, t.Name)
+	}
+	return strings.Join(nn, " ")
+}
+
+func (s *Exporting) Golden_suites_and_sub_tests_in_reported_order(
+	t *T,
+) {
+	out := &bytes.Buffer{}
+
+	passed, err := Export(&InitFactories{Watcher: fxGoldenExport(t)},
+		out, ExportJSON)
+	t.FatalOn(err)
+	t.True(passed)
+
+	r := &exportReport{}
+	t.FatalOn(json.Unmarshal(out.Bytes(), r))
+	t.FatalIfNot(t.Eq(1, len(r.Packages)))
+	p := r.Packages[0]
+	t.Eq("export", p.Package)
+	t.Eq("TestGo TestGoSubs", exportNames(p.Tests))
+	t.FatalIfNot(t.Eq("a_sub b_sub", exportNames(p.Tests[1].Tests)))
+	t.Contains(fmt.Sprint(p.Tests[1].Tests[0].Output), "sub log")
+	t.FatalIfNot(t.Eq(1, len(p.Suites)))
+	suite := p.Suites[0]
+	t.Eq("TestOrdered", suite.Runner)
+	t.Eq("Written_first Has_nested_sub_tests A_written_last",
+		exportNames(suite.Tests))
+	t.FatalIfNot(t.Eq("nested", exportNames(suite.Tests[1].Tests)))
+	t.Contains(fmt.Sprint(suite.Tests[1].Tests[0].Output), "nested log")
+	t.Contains(fmt.Sprint(suite.InitOutput), "init log")
+	t.Contains(fmt.Sprint(suite.FinalizeOutput), "finalize log")
+	t.True(suite.Tests[0].Duration > 0)
+	t.True(p.Duration > suite.Duration)
+}
+
+func (s *Exporting) Json_durations_are_numbers_of_seconds(t *T) {
+	out := &bytes.Buffer{}
+	_, err := Export(&InitFactories{Watcher: fxHeadless(t, false)},
+		out, ExportJSON)
+	t.FatalOn(err)
+
+	r := struct {
+		Packages []struct{ Duration interface{} }
+	}{}
+	t.FatalOn(json.Unmarshal(out.Bytes(), &r))
+
+	t.FatalIfNot(t.Eq(1, len(r.Packages)))
+	d, ok := r.Packages[0].Duration.(float64)
+	t.True(ok)
+	t.True(d > 0 && d < 60)
+}
+
+func (s *Exporting) Fails_on_unknown_format(t *T) {
+	_, err := Export(&InitFactories{Watcher: fxHeadless(t, false)},
+		&bytes.Buffer{}, "xml")
+
+	t.ErrMatched(err, "unknown format")
+}
+
+func (s *Exporting) Button_writes_current_state_to_export_file(t *T) {
+	dir := t.FS().Tmp()
+	bb := &buttons{
+		config:    &Config{Export: ExportJUnit},
+		exportDir: dir.Path(),
+		modelState: &modelState{Mutex: &sync.Mutex{},
+			state: &state{pp: pkgs{}}},
+	}
+	file := filepath.Join(dir.Path(), ExportFiles[ExportJUnit])
+
+	t.Eq(fmt.Sprintf(exportedFmt, file), bb.export())
+
+	content, err := os.ReadFile(file)
+	t.FatalOn(err)
+	t.Contains(string(content), "<testsuites")
+}
+
+func (s *Exporting) Button_writes_to_a_temporary_directory(t *T) {
+	bb := &buttons{
+		config: &Config{},
+		modelState: &modelState{Mutex: &sync.Mutex{},
+			state: &state{pp: pkgs{}}},
+	}
+
+	msg := bb.export()
+
+	t.FatalIfNot(t.True(bb.exportDir != ""))
+	defer os.RemoveAll(bb.exportDir)
+	file := filepath.Join(bb.exportDir, ExportFiles[ExportJSON])
+	t.Eq(fmt.Sprintf(exportedFmt, file), msg)
+	t.True(strings.HasPrefix(file, os.TempDir()))
+	_, err := os.Stat(file)
+	t.FatalOn(err)
+}
+
+func TestExporting(t *testing.T) {
+	t.Parallel()
+	Run(&Exporting{}, t)
+}
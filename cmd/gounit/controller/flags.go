@@ -0,0 +1,164 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	flagsErr = "gounit: flags: %v"
+)
+
+const usage = `usage: gounit [flags] [-- go test flags]
+
+Gounit watches the testing packages of a go module's directory and
+reports their test results on changes.  Flags override the settings of
+a found .gounit.toml or .gounit.json configuration file.  Arguments
+after -- are passed through to each go test run.
+
+`
+
+// Flags are gounit's parsed command line arguments, see [ParseFlags].
+type Flags struct {
+
+	// Config is the configuration of the watched directory whose
+	// settings are overridden by set flags.
+	Config *Config
+
+	// Once requests to run all tests once without terminal ui, see
+	// [Once].
+	Once bool
+
+	// Plain requests to watch without terminal ui writing each report
+	// as plain text to stdout, see [Plain].
+	Plain bool
+
+	// Export requests to run all tests once without terminal ui and
+	// to write their results in the given format to stdout, see
+	// [Export].
+	Export string
+
+	// Color requests ANSI colored reports of front ends without
+	// terminal ui.
+	Color bool
+}
+
+// ParseFlags parses given command line arguments (without the command
+// name) whereas usage and parse errors are reported to given output.
+// The configuration of the directory given by the -dir flag
+// respectively of the working directory is loaded (see [LoadConfig])
+// and overridden by set flags.  The -run flag and the arguments after
+// "--" are added to the go test flags of each test run; a package
+// without a test matching -run passes without tests.  flag.ErrHelp is
+// returned if the -h or -help flag is set.
+func ParseFlags(args []string, output io.Writer) (*Flags, error) {
+	var passthrough []string
+	for i, a := range args {
+		if a != "--" {
+			continue
+		}
+		args, passthrough = args[:i], args[i+1:]
+		break
+	}
+
+	fs := flag.NewFlagSet("gounit", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprint(output, usage)
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", "",
+		"watched `directory`; defaults to the working directory")
+	timeout := fs.Duration("timeout", 0,
+		"`duration` after which a package's test run is canceled")
+	interval := fs.Duration("interval", 0,
+		"`duration` between two checks for changes")
+	ignore := fs.String("ignore", "", "comma separated `names` of "+
+		"directories which are ignored in addition to the defaults")
+	vet := fs.Bool("vet", false, "switch vet initially on")
+	race := fs.Bool("race", false, "switch race initially on")
+	stats := fs.Bool("stats", false, "switch stats initially on")
+	run := fs.String("run", "",
+		"run only tests and suites matching given `regexp`")
+	once := fs.Bool("once", false, "run all tests once, report them "+
+		"to stdout and exit non-zero on failure")
+	plain := fs.Bool("plain", false, "watch without terminal ui "+
+		"and write each report as plain text to stdout")
+	export := fs.String("export", "", "run all tests once, write "+
+		"their results in given `format` (json or junit) to stdout "+
+		"and exit non-zero on failure")
+	color := fs.Bool("color", false, "color reports without "+
+		"terminal ui with ANSI escape sequences")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		err := fmt.Errorf(flagsErr, "unexpected argument: "+fs.Arg(0))
+		fmt.Fprintln(output, err)
+		fs.Usage()
+		return nil, err
+	}
+	if *once && *plain || *export != "" && (*once || *plain) {
+		return nil, fmt.Errorf(flagsErr,
+			"-once, -plain and -export are mutually exclusive")
+	}
+	if _, ok := ExportFiles[*export]; *export != "" && !ok {
+		return nil, fmt.Errorf(flagsErr, unknownFormat(*export))
+	}
+	if _, err := regexp.Compile(*run); err != nil {
+		return nil, fmt.Errorf(flagsErr, err)
+	}
+
+	if *dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf(flagsErr, err)
+		}
+		*dir = wd
+	}
+	abs, err := filepath.Abs(*dir)
+	if err != nil {
+		return nil, fmt.Errorf(flagsErr, err)
+	}
+	cfg, err := LoadConfig(abs)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Dir = abs
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "timeout":
+			cfg.Timeout = Duration(*timeout)
+		case "interval":
+			cfg.Interval = Duration(*interval)
+		case "ignore":
+			for _, i := range strings.Split(*ignore, ",") {
+				if strings.TrimSpace(i) == "" {
+					continue
+				}
+				cfg.Ignore = append(cfg.Ignore, strings.TrimSpace(i))
+			}
+		case "vet":
+			cfg.Vet = *vet
+		case "race":
+			cfg.Race = *race
+		case "stats":
+			cfg.Stats = *stats
+		case "run":
+			cfg.Args = append(cfg.Args, "-run="+*run)
+		}
+	})
+	cfg.Args = append(cfg.Args, passthrough...)
+	return &Flags{Config: cfg, Once: *once, Plain: *plain,
+		Export: *export, Color: *color}, nil
+}
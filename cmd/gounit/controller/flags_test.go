@@ -0,0 +1,118 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+)
+
+type CommandLine struct{ Suite }
+
+func (s *CommandLine) SetUp(t *T) { t.Parallel() }
+
+// fxConfigured returns the path of a temporary module directory with a
+// configuration file having given content.
+func fxConfigured(t *T, content string) string {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/flags")
+	dir.MkFile(".gounit.toml", []byte(content))
+	return dir.Path()
+}
+
+func (s *CommandLine) Defaults_to_configured_settings(t *T) {
+	dir := fxConfigured(t, "timeout = \"2m\"\nrace = true\n")
+
+	flags, err := ParseFlags([]string{"-dir", dir}, &bytes.Buffer{})
+	t.FatalOn(err)
+
+	t.Eq(dir, flags.Config.Dir)
+	t.Eq(2*time.Minute, time.Duration(flags.Config.Timeout))
+	t.Eq(raceOn|affectedOn, flags.Config.onMask())
+}
+
+func (s *CommandLine) Overrides_configured_settings(t *T) {
+	dir := fxConfigured(t, "timeout = \"2m\"\nrace = true\n"+
+		"ignore = [\"vendor\"]\n")
+
+	flags, err := ParseFlags([]string{"-dir", dir, "-timeout=1m",
+		"-interval=50ms", "-race=false", "-vet", "-stats",
+		"-ignore=tools, gen"}, &bytes.Buffer{})
+	t.FatalOn(err)
+
+	t.Eq(time.Minute, time.Duration(flags.Config.Timeout))
+	t.Eq(50*time.Millisecond, time.Duration(flags.Config.Interval))
+	t.Eq(vetOn|statsOn|affectedOn, flags.Config.onMask())
+	t.Eq([]string{"vendor", "tools", "gen"}, flags.Config.Ignore)
+}
+
+func (s *CommandLine) Adds_run_and_passed_through_go_test_flags(t *T) {
+	dir := fxConfigured(t, "args = [\"-failfast\"]\n")
+
+	flags, err := ParseFlags([]string{"-dir", dir, "-run", "^TestA",
+		"--", "-count=1", "-v"}, &bytes.Buffer{})
+	t.FatalOn(err)
+
+	t.Eq([]string{"-failfast", "-run=^TestA", "-count=1", "-v"},
+		flags.Config.Args)
+	t.Eq([]string{"-failfast", "-run=^TestA", "-count=1", "-v"},
+		flags.Config.profiles()[0].Args)
+}
+
+func (s *CommandLine) Fail_on_unexpected_arguments(t *T) {
+	dir := fxConfigured(t, "")
+	out := &bytes.Buffer{}
+
+	_, err := ParseFlags([]string{"-dir", dir, "pkg"}, out)
+
+	t.ErrMatched(err, "unexpected argument: pkg")
+	t.Contains(out.String(), "usage: gounit")
+}
+
+func (s *CommandLine) Fail_on_invalid_run_expression(t *T) {
+	dir := fxConfigured(t, "")
+
+	_, err := ParseFlags([]string{"-dir", dir, "-run", "(a"},
+		&bytes.Buffer{})
+
+	t.ErrMatched(err, "gounit: flags")
+}
+
+func (s *CommandLine) Fail_on_once_and_plain(t *T) {
+	dir := fxConfigured(t, "")
+
+	_, err := ParseFlags([]string{"-dir", dir, "-once", "-plain"},
+		&bytes.Buffer{})
+
+	t.ErrMatched(err, "mutually exclusive")
+}
+
+func (s *CommandLine) Fail_on_unknown_export_format(t *T) {
+	dir := fxConfigured(t, "")
+
+	_, err := ParseFlags([]string{"-dir", dir, "-export=xml"},
+		&bytes.Buffer{})
+
+	t.ErrMatched(err, "unknown format")
+}
+
+func (s *CommandLine) Report_usage_on_help(t *T) {
+	out := &bytes.Buffer{}
+
+	_, err := ParseFlags([]string{"-h"}, out)
+
+	t.True(errors.Is(err, flag.ErrHelp))
+	t.Contains(out.String(), "-timeout duration")
+}
+
+func TestCommandLine(t *testing.T) {
+	t.Parallel()
+	Run(&CommandLine{}, t)
+}
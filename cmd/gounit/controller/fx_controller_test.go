@@ -78,7 +78,8 @@ func (tt *Testing) isOn(o onMask) bool {
 // appears in the view, derived from given short label like "vet",
 // "race" or "stats".
 func (tt *Testing) switchButtonLabel(shortLabel string) (string, string) {
-	bb := switchButtons(tt._controller.bb.isOn, nil)
+	bb := switchButtons(tt._controller.bb.isOn,
+		tt._controller.bb.profileLabel(), nil)
 	lbl, vw := "", ""
 	bb.ForNew(func(bd view.ButtonDef) error {
 		if lbl != "" {
@@ -282,6 +283,13 @@ func fxSource(t *gounit.T, relDir string) *Testing {
 	)
 }
 
+// fxEdit appends a comment to given golden directory's file with given
+// relative name to change its content.
+func fxEdit(golden *tfs.Dir, relName string) {
+	golden.WriteContent(relName, append(golden.FileContent(relName),
+		[]byte("\n// edited\n")...))
+}
+
 func fxSourceTouched(
 	t *gounit.T, relDir string, touch string,
 ) *Testing {
@@ -433,6 +441,10 @@ func fxInit(
 type watcherMock struct {
 	c     chan *model.PackagesDiff
 	watch func() (<-chan *model.PackagesDiff, uint64, error)
+
+	// profiles records the profiles set by the controller.
+	mutex    sync.Mutex
+	profiles []*model.Profile
 }
 
 const (
@@ -444,6 +456,22 @@ const (
 func (m *watcherMock) ModuleName() string { return mckModule }
 func (m *watcherMock) ModuleDir() string  { return mckModuleDir }
 func (m *watcherMock) SourcesDir() string { return mckSourceDir }
+func (m *watcherMock) SetProfile(p *model.Profile) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.profiles = append(m.profiles, p)
+}
+
+// lastProfile returns the profile which was set last; nil if none.
+func (m *watcherMock) lastProfile() *model.Profile {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if len(m.profiles) == 0 {
+		return nil
+	}
+	return m.profiles[len(m.profiles)-1]
+}
+
 func (m *watcherMock) Watch() (
 	<-chan *model.PackagesDiff, uint64, error,
 ) {
@@ -57,8 +57,11 @@ selectable line.  Pressing enter on a highlighted line will select
 it respectively fold/unfold the selected package/suite accordingly.
 
 Buttons in the bottom may be selected by clicking on them or pressing
-the embraced key. "[s]witches" switches to a button bar with switches
-for test runs:
+the embraced key. "[e]xport" writes the current test results of all
+reported packages to gounit-report.json in a temporary directory whose
+path is shown in the message bar, or to gounit-report.xml as JUnit XML
+if the configured export format is junit.  "[s]witches" switches to a
+button bar with switches for test runs:
 
 [v]et switches the Go vet execution for test-runs on and off.  I.e.
        on [v]et=off the "go test" command is run with the "-vet=off"
@@ -81,5 +84,16 @@ effect at the next package source-change or if a package is selected.
        status bar the same information for the total of the watched 
        source directory is provided.
 
+[a]ffected switches rerunning affected packages on and off.  I.e. if
+       [a]ffected=on a change of a package's (non-test) sources also
+       reruns the tests of the watched packages which import the
+       changed package directly or indirectly.  It is on by default.
+
+[p]rofile switches to the next configured run profile if any.  A
+       profile determines the build tags, environment and go test
+       flags of test runs as well as the test files which are
+       reported, e.g. [p]rofile=integration reports and runs the
+       tests behind an integration build tag.
+
 Happy coding!
 `
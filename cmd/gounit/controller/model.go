@@ -5,13 +5,16 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/slukits/gounit/cmd/gounit/model"
 	"github.com/slukits/gounit/cmd/gounit/view"
+	"github.com/slukits/gounit/pkg/testjson"
 )
 
 // state represents the current state of a watched source directory from
@@ -89,6 +92,16 @@ type modelState struct {
 
 	// isSuspended controls if model-state change updates the view.
 	isSuspended bool
+
+	// pool limits the number of concurrent test runs.
+	pool *pool
+
+	// queued is the number of test runs waiting for a worker.
+	queued int
+
+	// generations tracks the in-flight test runs of packages which are
+	// canceled if a newer diff reports their package.
+	generations *generations
 }
 
 func msgUpdater(mdlName, srcDir string) func(*state) string {
@@ -159,6 +172,12 @@ type reporter interface {
 	Folded() reporter
 }
 
+// export writes the test results of the current state's packages in
+// given format to given writer.
+func (s *modelState) export(w io.Writer, format string) error {
+	return writeExport(w, s.clone(false).pp, format)
+}
+
 // updateState through a change in the watched source directory.
 func (s *modelState) updateState(st *state) {
 	if st.latestPkg != "" && len(st.ee) > 0 && !st.ee[st.latestPkg] {
@@ -182,7 +201,8 @@ func (s *modelState) lineListener(idx int) {
 		report := newReport(st, rt, idx)
 		s.updateReport(st, report, status)
 	}
-	if rt == rprPackage && ensureRequestedPackageRun(update, st, idx) {
+	if rt == rprPackage &&
+		ensureRequestedPackageRun(s.generations, update, st, idx) {
 		return
 	}
 	update()
@@ -193,7 +213,9 @@ func (s *modelState) lineListener(idx int) {
 // true if a package's tests were rerun.  This function covers the use
 // case if the user requests all packages folded, then for example turns
 // vetting on and finally selects a package to report.
-func ensureRequestedPackageRun(cb func(), st *state, idx int) bool {
+func ensureRequestedPackageRun(
+	gg *generations, cb func(), st *state, idx int,
+) bool {
 	pID, ok := findReportLine(
 		st.view[0].(*report), idx, view.PackageFoldedLine)
 	if !ok {
@@ -203,7 +225,7 @@ func ensureRequestedPackageRun(cb func(), st *state, idx int) bool {
 	if pkg.om == st.isOn {
 		return false
 	}
-	go rerunTests(cb, pkg, st)
+	go rerunTests(gg, cb, pkg, st)
 	return true
 }
 
@@ -255,7 +277,7 @@ func (s *modelState) setOnFlag(om onMask) {
 	st.isOn |= om
 
 	if st.latestPkg != "" && om&(raceOn|vetOn) != 0 {
-		go rerunTests(func() {
+		go rerunTests(s.generations, func() {
 			stt := newStatus(st.pp, st.isOn)
 			r := newReport(st, rprDefault, -1)
 			s.updateReport(st, r, stt)
@@ -295,6 +317,7 @@ func (s *modelState) updateReport(
 func (s *modelState) updateView(
 	st *state, report *report, status *view.Statuser,
 ) {
+	status.Queued = s.queued
 	report.lst = s.lineListener
 	report.flags = view.RpClearing
 	st.view = []interface{}{report, status, s.msgUpdater(st)}
@@ -304,6 +327,69 @@ func (s *modelState) updateView(
 	s.viewUpdater(st.view...)
 }
 
+// progressFmt formats the message bar content while a package's tests
+// are running.
+const progressFmt = "%s: running: %s (passed: %d; failed: %d)"
+
+// progress returns a callback for a test run of the package with given
+// ID which reports in the message bar the most recently started test
+// which hasn't ended yet.  The message bar is reset by the report of
+// the test run's results.
+func (s *modelState) progress(ID string) func(*model.Progress) {
+	running := []string{}
+	return func(p *model.Progress) {
+		if p.Action == testjson.Run {
+			running = append(running, p.Test)
+		} else {
+			for i, t := range running {
+				if t == p.Test {
+					running = append(running[:i], running[i+1:]...)
+					break
+				}
+			}
+		}
+		if len(running) == 0 {
+			return
+		}
+		s.Lock()
+		defer s.Unlock()
+		if s.isSuspended {
+			return
+		}
+		s.viewUpdater(fmt.Sprintf(progressFmt, ID,
+			running[len(running)-1], p.Passed, p.Failed))
+	}
+}
+
+// updateQueued updates the status bar with given number of test runs
+// waiting for a worker.
+func (s *modelState) updateQueued(n int) {
+	s.Lock()
+	defer s.Unlock()
+	s.queued = n
+	for i, v := range s.view {
+		status, ok := v.(*view.Statuser)
+		if !ok {
+			continue
+		}
+		updated := *status
+		updated.Queued = n
+		s.view[i] = &updated
+		if !s.isSuspended {
+			s.viewUpdater(&updated)
+		}
+		return
+	}
+}
+
+// isAffectedOn returns true iff packages affected by a changed package
+// are rerun.
+func (s *modelState) isAffectedOn() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.isOn&affectedOn != 0
+}
+
 func (s *modelState) removeOneFlag(om onMask) {
 	s.Lock()
 	defer s.Unlock()
@@ -318,9 +404,12 @@ func (s *modelState) removeOneFlag(om onMask) {
 // rerunTests reruns the tests of given package using the given state's
 // isOn property to calculate the (usually modified) run-arguments for
 // the test-run.  Finally given model-states report is updated with the
-// rerun package's report.
-func rerunTests(cb func(), p *pkg, st *state) {
-	rr, err := p.Run(translateToRunMask(st.isOn))
+// rerun package's report.  The rerun is canceled if a newer diff
+// reports the package or the watching stops.
+func rerunTests(gg *generations, cb func(), p *pkg, st *state) {
+	ctx, cancel := gg.current(p.ID())
+	defer cancel()
+	rr, err := p.Run(ctx, translateToRunMask(st.isOn))
 	p.runResult = &runResult{Results: rr, err: err, om: st.isOn}
 	if p.HasErr() && !st.ee[p.ID()] {
 		st.ee[p.ID()] = true
@@ -343,69 +432,272 @@ func translateToRunMask(om onMask) model.RunMask {
 	return rm
 }
 
+// watch processes reported packages diffs by running the tests of
+// updated packages and updating the model state with their results.  A
+// package's in-flight test run is canceled if a newer diff reports the
+// package again; i.e. only the results of a package's latest generation
+// are applied to the model state.  Is given afterUpdate channel not nil
+// it receives a value each time a diff has been processed.
 func watch(
 	watched <-chan *model.PackagesDiff,
 	mdl *modelState,
 	afterUpdate chan bool,
 ) {
-	for diff := range watched {
-		if diff == nil {
-			return
-		}
-		st := mdl.clone(false)
-		if st.ee == nil {
-			st.ee = map[string]bool{}
+	if mdl.pool == nil {
+		mdl.pool = newPool(0, mdl.updateQueued)
+	}
+	if mdl.generations == nil {
+		mdl.generations = newGenerations()
+	}
+	gg, done := mdl.generations, make(chan *generation)
+	quit := make(chan struct{})
+	defer close(quit)
+	for {
+		select {
+		case diff := <-watched:
+			if diff == nil {
+				gg.cancelAll()
+				return
+			}
+			g := gg.next(diff, mdl.isAffectedOn())
+			go g.run(gg, mdl, done, quit)
+		case g := <-done:
+			if rerun := g.apply(gg, mdl); rerun != nil {
+				go rerun.run(gg, mdl, done, quit)
+			}
+			if afterUpdate != nil {
+				afterUpdate <- true
+			}
 		}
-		diff.ForDel(func(tp *model.TestingPackage) (stop bool) {
-			delete(st.pp, tp.ID())
-			delete(st.ee, tp.ID())
-			return
-		})
-		rslt, n := make(chan *pkg), 0
-		// TODO: since we don't care about the reported package order we
-		// should be able to remove the sorting of them from the model.
-		diff.For(func(tp *model.TestingPackage) (stop bool) {
-			n++ // count expected results
-			go run(&pkg{TestingPackage: tp}, st.isOn, rslt)
+	}
+}
+
+// generations keeps track of the latest generation, i.e. the latest
+// reporting diff, of each package and of their in-flight test runs.
+type generations struct {
+	*sync.Mutex
+	n       int
+	latest  map[string]int
+	cancels map[string][]context.CancelFunc
+}
+
+func newGenerations() *generations {
+	return &generations{
+		Mutex:   &sync.Mutex{},
+		latest:  map[string]int{},
+		cancels: map[string][]context.CancelFunc{},
+	}
+}
+
+// generation holds the packages reported by a packages diff and the
+// results of their test runs.  A rerun generation holds the failing
+// packages which are rerun after a package was fixed.
+type generation struct {
+	n       int
+	updated []*model.TestingPackage
+	deleted []string
+	pp      []*pkg
+	rerun   bool
+}
+
+// next creates the next generation from given diff and cancels the
+// in-flight test runs of the packages it reports.  Packages which are
+// only affected by a changed package are skipped unless withAffected is
+// true.
+func (gg *generations) next(
+	diff *model.PackagesDiff, withAffected bool,
+) *generation {
+	gg.Lock()
+	defer gg.Unlock()
+	gg.n++
+	g := &generation{n: gg.n}
+	// TODO: since we don't care about the reported package order we
+	// should be able to remove the sorting of them from the model.
+	diff.For(func(tp *model.TestingPackage) (stop bool) {
+		if tp.Affected() && !withAffected {
 			return
+		}
+		g.updated = append(g.updated, tp)
+		return
+	})
+	diff.ForDel(func(tp *model.TestingPackage) (stop bool) {
+		g.deleted = append(g.deleted, tp.ID())
+		return
+	})
+	for _, tp := range g.updated {
+		gg.supersede(tp.ID(), g.n)
+	}
+	for _, ID := range g.deleted {
+		gg.supersede(ID, g.n)
+	}
+	return g
+}
+
+// rerun creates a generation rerunning given failing packages and
+// cancels their in-flight test runs.
+func (gg *generations) rerun(pp []*pkg) *generation {
+	gg.Lock()
+	defer gg.Unlock()
+	gg.n++
+	g := &generation{n: gg.n, rerun: true}
+	for _, p := range pp {
+		g.updated = append(g.updated, p.TestingPackage)
+		gg.supersede(p.ID(), g.n)
+	}
+	return g
+}
+
+func (gg *generations) supersede(ID string, n int) {
+	gg.release(ID)
+	gg.latest[ID] = n
+}
+
+// release cancels the test runs of the package with given ID.
+func (gg *generations) release(ID string) {
+	for _, cancel := range gg.cancels[ID] {
+		cancel()
+	}
+	delete(gg.cancels, ID)
+}
+
+// start returns the context for a test run of the package with given ID
+// of generation n which is canceled if a newer generation reports the
+// package.
+func (gg *generations) start(ID string, n int) context.Context {
+	gg.Lock()
+	defer gg.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	if gg.latest[ID] > n {
+		cancel()
+		return ctx
+	}
+	gg.cancels[ID] = append(gg.cancels[ID], cancel)
+	return ctx
+}
+
+// current returns the context and its cancel function for a test run of
+// the package with given ID which is not triggered by a diff.  The
+// context is canceled if a newer generation reports the package.
+func (gg *generations) current(
+	ID string,
+) (context.Context, context.CancelFunc) {
+	gg.Lock()
+	defer gg.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	gg.cancels[ID] = append(gg.cancels[ID], cancel)
+	return ctx, cancel
+}
+
+// isLatest returns true iff generation n is the latest generation of
+// the package with given ID; in this case its cancel functions are
+// released.
+func (gg *generations) isLatest(ID string, n int) bool {
+	gg.Lock()
+	defer gg.Unlock()
+	if gg.latest[ID] != n {
+		return false
+	}
+	gg.release(ID)
+	return true
+}
+
+func (gg *generations) cancelAll() {
+	gg.Lock()
+	defer gg.Unlock()
+	for ID := range gg.cancels {
+		gg.release(ID)
+	}
+}
+
+// run runs the tests of given generation's updated packages and reports
+// the generation to given done channel once all runs are finished
+// unless given quit channel is closed.
+func (g *generation) run(
+	gg *generations, mdl *modelState, done chan *generation,
+	quit chan struct{},
+) {
+	st := mdl.clone(false)
+	rslt := make(chan *pkg)
+	for _, tp := range g.updated {
+		ctx, p := gg.start(tp.ID(), g.n), &pkg{TestingPackage: tp}
+		mdl.pool.submit(&job{
+			failing: st.ee[tp.ID()],
+			modTime: tp.ModTime,
+			run: func() {
+				run(ctx, p, st.isOn, rslt, mdl.progress(p.ID()))
+			},
 		})
-		rerunEE := false
-		for i := 0; i < n; i++ {
-			p := <-rslt
-			st.pp[p.ID()] = p
-			if p.HasErr() || !p.Passed() {
-				st.ee[p.ID()] = true
-				continue
-			}
-			if st.ee[p.ID()] {
-				delete(st.ee, p.ID())
-				if n == 1 && len(st.ee) > 0 {
-					rerunEE = true
-				}
-			}
+	}
+	for range g.updated {
+		g.pp = append(g.pp, <-rslt)
+	}
+	select {
+	case done <- g:
+	case <-quit:
+	}
+}
+
+// apply updates the model state with the results of given generation's
+// packages which haven't been superseded by a newer generation.  The
+// model state isn't updated if all of them have been superseded.  Is a
+// single failing package fixed while other packages are still failing
+// the generation rerunning the failing packages is returned.
+func (g *generation) apply(gg *generations, mdl *modelState) *generation {
+	deleted, pp := []string{}, []*pkg{}
+	for _, ID := range g.deleted {
+		if gg.isLatest(ID, g.n) {
+			deleted = append(deleted, ID)
 		}
-		if rerunEE {
-			for ID := range st.ee {
-				go run(st.pp[ID], st.isOn, rslt)
-			}
-			for i := 0; i < len(st.ee); i++ {
-				p := <-rslt
-				if !p.HasErr() && p.Passed() {
-					delete(st.ee, p.ID())
-					p.inf = nil
-				}
-			}
+	}
+	for _, p := range g.pp {
+		if gg.isLatest(p.ID(), g.n) {
+			pp = append(pp, p)
+		}
+	}
+	if len(deleted)+len(pp) == 0 {
+		return nil
+	}
+	st := mdl.clone(false)
+	if st.ee == nil {
+		st.ee = map[string]bool{}
+	}
+	for _, ID := range deleted {
+		delete(st.pp, ID)
+		delete(st.ee, ID)
+	}
+	rerunEE := false
+	for _, p := range pp {
+		st.pp[p.ID()] = p
+		if p.HasErr() || !p.Passed() {
+			st.ee[p.ID()] = true
+			continue
 		}
-		mdl.updateState(st)
-		if afterUpdate != nil {
-			afterUpdate <- true
+		if st.ee[p.ID()] {
+			delete(st.ee, p.ID())
+			if !g.rerun && len(pp) == 1 && len(st.ee) > 0 {
+				rerunEE = true
+			}
 		}
 	}
+	mdl.updateState(st)
+	if !rerunEE {
+		return nil
+	}
+	ee := []*pkg{}
+	for ID := range st.ee {
+		ee = append(ee, st.pp[ID])
+	}
+	return gg.rerun(ee)
 }
 
-func run(p *pkg, om onMask, rslt chan *pkg) {
-	rr, err := p.Run(translateToRunMask(om))
-	p.TrimTo(rr)
+func run(
+	ctx context.Context, p *pkg, om onMask, rslt chan *pkg,
+	progress func(*model.Progress),
+) {
+	rr, err := p.RunWithProgress(ctx, translateToRunMask(om), progress)
+	if rr != nil {
+		p.TrimTo(rr)
+	}
 	p.runResult = &runResult{Results: rr, err: err, om: om}
 	rslt <- p
 }
@@ -420,8 +712,8 @@ type runResult struct {
 }
 
 type info struct {
-	n, f, s int
-	d       time.Duration
+	n, f, s, fl int
+	d           time.Duration
 }
 
 type pkg struct {
@@ -437,11 +729,12 @@ func (p *pkg) info() (n, f, s int, d time.Duration) {
 		return 0, 0, 0, 0
 	}
 	if p.inf == nil {
-		goSuites := 0
+		goSuites, fl := 0, 0
 		p.ForTest(func(t *model.Test) {
 			r := p.OfTest(t)
 			n += r.Len()
 			f += r.LenFailed()
+			fl += r.LenFlaky()
 			if r.HasSubs() {
 				goSuites++
 			}
@@ -453,13 +746,24 @@ func (p *pkg) info() (n, f, s int, d time.Duration) {
 			}
 			n += r.Len()
 			f += r.LenFailed()
+			fl += r.LenFlaky()
 		})
-		p.inf = &info{n: n, f: f, d: p.Duration,
+		p.inf = &info{n: n, f: f, fl: fl, d: p.Duration,
 			s: p.LenSuites() + goSuites}
 	}
 	return p.inf.n, p.inf.f, p.inf.s, p.inf.d
 }
 
+// flaky returns the number of tests of p which passed only after a
+// retry.
+func (p *pkg) flaky() int {
+	if p.HasErr() {
+		return 0
+	}
+	p.info()
+	return p.inf.fl
+}
+
 func (p *pkg) HasFailedSuite() bool {
 	failed := false
 	p.ForSuite(func(ts *model.TestSuite) {
@@ -552,4 +856,8 @@ type Watcher interface {
 	// Watch is a function whose returned channel watches a go modules
 	// packages sources whose tests runs are reported to a terminal ui.
 	Watch() (<-chan *model.PackagesDiff, uint64, error)
+
+	// SetProfile switches the profile of the watched sources, i.e. the
+	// build and run settings of its testing packages' reports.
+	SetProfile(*model.Profile)
 }
@@ -0,0 +1,177 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/slukits/gounit/cmd/gounit/model"
+	"github.com/slukits/gounit/cmd/gounit/view"
+)
+
+// Once runs the tests of all testing packages of given InitFactories'
+// watcher once without a terminal ui and writes for each package the
+// report the terminal ui would show for it followed by the status line
+// to given writer; ANSI colored iff color is true.  Of given
+// InitFactories only Watcher, Config, Workers and Profiles are used
+// with the same defaults [New] uses.  Returned passed is false if a
+// package failed to run or has failing tests.  An error is returned if
+// watching or writing fails.
+func Once(i *InitFactories, w io.Writer, color bool) (
+	passed bool, err error,
+) {
+	pp, om, err := runAll(i)
+	if err != nil {
+		return false, err
+	}
+	return writeOnce(w, pp, om, color)
+}
+
+// Export runs like [Once] the tests of all testing packages of given
+// InitFactories' watcher once and writes their results in given format
+// to given writer, i.e. either as JSON (see [ExportJSON]) or as JUnit
+// XML (see [ExportJUnit]).  Returned passed is false if a package
+// failed to run or has failing tests.  An error is returned for an
+// unknown format or if watching or writing fails.
+func Export(i *InitFactories, w io.Writer, format string) (
+	passed bool, err error,
+) {
+	if _, ok := ExportFiles[format]; !ok {
+		return false, fmt.Errorf(exportErr, unknownFormat(format))
+	}
+	pp, _, err := runAll(i)
+	if err != nil {
+		return false, err
+	}
+	if err := writeExport(w, pp, format); err != nil {
+		return false, err
+	}
+	return newExport(pp).Passed, nil
+}
+
+// runAll runs the tests of all testing packages of given
+// InitFactories' watcher once and returns them together with the
+// switches they were run with.
+func runAll(i *InitFactories) (pkgs, onMask, error) {
+	if i == nil {
+		i = &InitFactories{}
+	}
+	cfg := i.Config
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	watcher := i.Watcher
+	if watcher == nil {
+		watcher = cfg.watcher()
+	}
+	profiles, workers := i.Profiles, i.Workers
+	if len(profiles) == 0 {
+		profiles = cfg.profiles()
+	}
+	if workers == 0 {
+		workers = cfg.Workers
+	}
+	if len(profiles) > 0 {
+		watcher.SetProfile(profiles[0])
+	}
+	diffs, _, err := watcher.Watch()
+	if err != nil {
+		return nil, 0, fmt.Errorf(
+			WatcherErr, watcher.SourcesDir(), err)
+	}
+	if qw, ok := watcher.(interface{ QuitAll() }); ok {
+		defer qw.QuitAll()
+	}
+	diff := <-diffs
+	if diff == nil {
+		return nil, 0, fmt.Errorf(WatcherErr, watcher.SourcesDir(),
+			errors.New("watching stopped"))
+	}
+	pp, err := runOnce(diff, cfg.onMask(), workers)
+	if err != nil {
+		return nil, 0, fmt.Errorf(WatcherErr, watcher.SourcesDir(), err)
+	}
+	return pp, cfg.onMask(), nil
+}
+
+// runOnce runs the tests of given diff's packages with given number of
+// workers and given switches.  An error is returned if the diff's
+// testing packages can't be loaded.
+func runOnce(diff *model.PackagesDiff, om onMask, workers int) (
+	pkgs, error,
+) {
+	pool, rslt, n := newPool(workers, nil), make(chan *pkg), 0
+	err := diff.For(func(tp *model.TestingPackage) (stop bool) {
+		n++
+		p := &pkg{TestingPackage: tp}
+		pool.submit(&job{modTime: tp.ModTime, run: func() {
+			run(context.Background(), p, om, rslt, nil)
+		}})
+		return false
+	})
+	pp := pkgs{}
+	for ; n > 0; n-- {
+		p := <-rslt
+		pp[p.ID()] = p
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
+// writeOnce writes the reports of given packages ordered by their IDs
+// followed by their status to given writer.  A package whose tests
+// couldn't be run is reported with the error of its run.
+func writeOnce(w io.Writer, pp pkgs, om onMask, color bool) (
+	passed bool, err error,
+) {
+	ran, ids, failed := pkgs{}, []string{}, false
+	for id, p := range pp {
+		ids = append(ids, id)
+		if p.Results == nil {
+			failed = true
+			continue
+		}
+		ran[id] = p
+	}
+	sort.Strings(ids)
+	status := newStatus(ran, om)
+	for _, id := range ids {
+		p := pp[id]
+		if p.Results == nil {
+			r := &report{ll: []string{id, indent + p.err.Error()},
+				llMasks: linesMask{0: view.PackageLine | view.Failed,
+					1: view.OutputLine}}
+			if err := writeReport(w, r, color); err != nil {
+				return false, err
+			}
+			if _, err := fmt.Fprintln(w); err != nil {
+				return false, err
+			}
+			continue
+		}
+		st := &state{pp: pkgs{id: p}, ee: map[string]bool{},
+			isOn: om, latestPkg: id}
+		if p.HasErr() || !p.Passed() {
+			st.ee[id] = true
+		}
+		r := newReport(st, rprDefault, 0)
+		if err := writeReport(w, r, color); err != nil {
+			return false, err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return false, err
+		}
+	}
+	if err := writeStatus(w, status, color); err != nil {
+		return false, err
+	}
+	return !failed && !status.IsFailing(), nil
+}
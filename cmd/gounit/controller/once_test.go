@@ -0,0 +1,126 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/cmd/gounit/model"
+	"github.com/slukits/gounit/cmd/gounit/view"
+	"github.com/slukits/lines"
+)
+
+type Headless struct{ Suite }
+
+func (s *Headless) SetUp(t *T) { t.Parallel() }
+
+const (
+	fxPassingTest = "import \"testing\"\n\n" +
+		"func TestPass(t *testing.T) {}\n"
+	fxFailingTest = "import \"testing\"\n\n" +
+		"func TestFail(t *testing.T) { t.Error(\"failed\") }\n"
+)
+
+// fxHeadless returns the watcher of a temporary module with a passing
+// package and iff failing is true also with a failing package.
+func fxHeadless(t *T, failing bool) *model.Sources {
+	dir := t.FS().Tmp()
+	dir.MkMod("example.com/headless")
+	pass, _ := dir.Mk("pass")
+	pass.MkPkgTest("pass", []byte(fxPassingTest))
+	if failing {
+		fail, _ := dir.Mk("fail")
+		fail.MkPkgTest("fail", []byte(fxFailingTest))
+	}
+	return &model.Sources{Dir: dir.Path(), Interval: time.Millisecond}
+}
+
+func (s *Headless) Reports_passing_packages_and_status(t *T) {
+	out := &bytes.Buffer{}
+
+	passed, err := Once(&InitFactories{Watcher: fxHeadless(t, false)},
+		out, false)
+	t.FatalOn(err)
+
+	t.True(passed)
+	t.Contains(out.String(), "pass")
+	t.Contains(out.String(), "pkgs/suites: 1/0; tests: 1/0")
+	t.Not.Contains(out.String(), ansiReset)
+}
+
+func (s *Headless) Reports_failing_tests_and_fails(t *T) {
+	out := &bytes.Buffer{}
+
+	passed, err := Once(&InitFactories{Watcher: fxHeadless(t, true)},
+		out, false)
+	t.FatalOn(err)
+
+	t.Not.True(passed)
+	t.Contains(out.String(), "./fail_test.go:5:")
+	t.Contains(out.String(), "failed")
+	t.True(strings.Index(out.String(), "fail") <
+		strings.Index(out.String(), "pass"))
+	t.True(strings.HasSuffix(out.String(),
+		"\npkgs/suites: 2/0; tests: 2/1\n"))
+}
+
+func (s *Headless) Passes_packages_without_tests_matching_run_flag(
+	t *T,
+) {
+	out := &bytes.Buffer{}
+	flags, err := ParseFlags([]string{"-dir", fxHeadless(t, true).Dir,
+		"-run", "^TestPass$"}, &bytes.Buffer{})
+	t.FatalOn(err)
+
+	passed, err := Once(&InitFactories{Config: flags.Config}, out, false)
+	t.FatalOn(err)
+
+	t.True(passed)
+	t.Not.Contains(out.String(), "no test events")
+	t.True(strings.HasSuffix(out.String(),
+		"\npkgs/suites: 2/0; tests: 1/0\n"))
+}
+
+func (s *Headless) Fails_if_testing_packages_cant_be_loaded(t *T) {
+	watcher := fxHeadless(t, false)
+	t.FS().Dir(filepath.Join(watcher.Dir, "pass", "dir_test.go"))
+
+	_, err := Once(&InitFactories{Watcher: watcher}, &bytes.Buffer{},
+		false)
+
+	t.ErrMatched(err, "gounit: watcher: .*dir_test.go")
+}
+
+func (s *Headless) Colors_failing_lines_and_status(t *T) {
+	out := &bytes.Buffer{}
+
+	_, err := Once(&InitFactories{Watcher: fxHeadless(t, true)},
+		out, true)
+	t.FatalOn(err)
+
+	t.Contains(out.String(), ansiFailed+"fail"+ansiReset)
+	t.Contains(out.String(),
+		ansiFailed+"pkgs/suites: 2/0; tests: 2/1"+ansiReset)
+}
+
+func (s *Headless) Right_aligns_filler_separated_content(t *T) {
+	l := textLine("pkg"+lines.Filler+"1/0 3ms", view.PackageLine, false)
+
+	t.Eq(textWidth, len(l))
+	t.True(strings.HasPrefix(l, "pkg "))
+	t.True(strings.HasSuffix(l, " 1/0 3ms"))
+	t.Eq(indent+ansiFlaky+"test"+ansiReset,
+		textLine(indent+"test"+lines.Filler, view.Flaky, true))
+}
+
+func TestHeadless(t *testing.T) {
+	t.Parallel()
+	Run(&Headless{}, t)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+plain.go provides the plain text front end of gounit's watch mode and
+renders reports and the status as plain text for front ends without a
+terminal ui, e.g. CI logs or editor terminals.  A report line's content
+after a lines.Filler is right aligned at textWidth and failed or flaky
+lines are optionally colored with ANSI escape sequences like the view
+does.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/slukits/gounit/cmd/gounit/view"
+	"github.com/slukits/lines"
+)
+
+// textWidth is the column at which the right aligned content of a plain
+// text report line ends.
+const textWidth = 80
+
+// ANSI escape sequences of colored plain text.
+const (
+	ansiFailed = "\x1b[97;41m"
+	ansiFlaky  = "\x1b[30;43m"
+	ansiPassed = "\x1b[30;42m"
+	ansiReset  = "\x1b[0m"
+)
+
+// Plain watches the testing packages of given InitFactories' watcher
+// without a terminal ui.  Each time the test results of a reported
+// packages diff are processed, the report and the status line the
+// terminal ui would show are written to given writer; ANSI colored iff
+// color is true.  Of given InitFactories only Watcher, Config, Workers
+// and Profiles are used with the same defaults [New] uses.  Plain
+// blocks until the watcher stops reporting diffs or until writing
+// fails.  In the later case the watcher is stopped, in-flight test runs
+// are canceled and the write error is returned once the watching has
+// ended; NOTE a watcher without a QuitAll method can't be stopped, i.e.
+// its watching is abandoned.
+func Plain(i *InitFactories, w io.Writer, color bool) error {
+	if i == nil {
+		i = &InitFactories{}
+	}
+	ensureInitArgs(i)
+	if len(i.Profiles) > 0 {
+		i.Watcher.SetProfile(i.Profiles[0])
+	}
+	diffs, _, err := i.Watcher.Watch()
+	if err != nil {
+		return fmt.Errorf(WatcherErr, i.Watcher.SourcesDir(), err)
+	}
+	qw, canQuit := i.Watcher.(interface{ QuitAll() })
+	if canQuit {
+		defer qw.QuitAll()
+	}
+	pw := &plainWriter{w: w, color: color, failed: make(chan error, 1)}
+	i.controller.model.viewUpdater = pw.update
+	i.controller.model.msgUpdater = func(*state) string { return "" }
+	done := make(chan struct{})
+	go func() {
+		i.watch(diffs, i.controller.model, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case err := <-pw.failed:
+		if canQuit {
+			qw.QuitAll() // closes diffs which cancels in-flight runs
+			<-done
+		}
+		return err
+	}
+}
+
+// plainWriter writes the reports and status lines of the model state's
+// view updates as plain text.
+type plainWriter struct {
+	mutex   sync.Mutex
+	w       io.Writer
+	color   bool
+	written bool
+	stopped bool
+	failed  chan error
+}
+
+// update writes given view update iff it contains a report; status
+// only updates like the number of queued test runs and message bar
+// updates like the progress of a test run are ignored.  The first
+// write error is reported to the failed channel after which updates
+// are ignored.
+func (pw *plainWriter) update(vv ...interface{}) {
+	pw.mutex.Lock()
+	defer pw.mutex.Unlock()
+	if pw.stopped {
+		return
+	}
+	var r *report
+	var status *view.Statuser
+	for _, v := range vv {
+		switch v := v.(type) {
+		case *report:
+			r = v
+		case *view.Statuser:
+			status = v
+		}
+	}
+	if r == nil {
+		return
+	}
+	if err := pw.write(r, status); err != nil {
+		pw.stopped = true
+		pw.failed <- err
+	}
+}
+
+// write writes given report followed by given status separated by a
+// blank line from a previously written update.
+func (pw *plainWriter) write(r *report, status *view.Statuser) error {
+	if pw.written {
+		if _, err := fmt.Fprintln(pw.w); err != nil {
+			return err
+		}
+	}
+	pw.written = true
+	if err := writeReport(pw.w, r, pw.color); err != nil {
+		return err
+	}
+	if status == nil {
+		return nil
+	}
+	return writeStatus(pw.w, status, pw.color)
+}
+
+// writeReport writes given report's lines without trailing blank lines
+// as plain text to given writer; ANSI colored iff color is true.
+func writeReport(w io.Writer, r *report, color bool) error {
+	ll := r.ll
+	for len(ll) > 0 && ll[len(ll)-1] == blankLine {
+		ll = ll[:len(ll)-1]
+	}
+	for idx, l := range ll {
+		_, err := fmt.Fprintln(w, textLine(l, r.LineMask(uint(idx)), color))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeStatus writes given status as plain text to given writer; ANSI
+// colored iff color is true.
+func writeStatus(w io.Writer, s *view.Statuser, color bool) error {
+	if !color {
+		_, err := fmt.Fprintln(w, s.String())
+		return err
+	}
+	c := ansiPassed
+	if s.IsFailing() {
+		c = ansiFailed
+	}
+	_, err := fmt.Fprintln(w, c+s.String()+ansiReset)
+	return err
+}
+
+// textLine renders given report line with given line mask as plain
+// text.
+func textLine(l string, lm view.LineMask, color bool) string {
+	cc := strings.Split(l, lines.Filler)
+	left := strings.TrimRight(cc[0], " ")
+	right := strings.TrimSpace(strings.Join(cc[1:], " "))
+	pad := ""
+	if right != "" {
+		n := textWidth - utf8.RuneCountInString(left) -
+			utf8.RuneCountInString(right)
+		if n < 1 {
+			n = 1
+		}
+		pad = strings.Repeat(" ", n)
+	}
+	if color {
+		left = colored(left, lm)
+	}
+	return left + pad + right
+}
+
+// colored wraps given line's content after its indentation into the
+// ANSI escape sequences of given line mask's formatting.
+func colored(l string, lm view.LineMask) string {
+	c := ""
+	switch {
+	case lm&view.Failed != 0:
+		c = ansiFailed
+	case lm&view.Flaky != 0:
+		c = ansiFlaky
+	}
+	content := strings.TrimLeft(l, " ")
+	if c == "" || content == "" {
+		return l
+	}
+	return l[:len(l)-len(content)] + c + content + ansiReset
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/cmd/gounit/model"
+)
+
+type PlainWatching struct{ Suite }
+
+func (s *PlainWatching) SetUp(t *T) { t.Parallel() }
+
+// syncBuffer is a bytes.Buffer which may be written and read
+// concurrently.
+type syncBuffer struct {
+	mutex sync.Mutex
+	bb    bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.bb.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.bb.String()
+}
+
+func (s *PlainWatching) Streams_reports_until_watching_stops(t *T) {
+	out, watcher := &syncBuffer{}, fxHeadless(t, true)
+	done := make(chan error)
+
+	go func() {
+		done <- Plain(&InitFactories{Watcher: watcher}, out, false)
+	}()
+	timeout := t.Timeout(20 * time.Second)
+	for !strings.Contains(out.String(), "tests: 2/1") {
+		select {
+		case <-timeout:
+			t.Fatal("plain watching didn't report")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	watcher.QuitAll()
+
+	select {
+	case err := <-done:
+		t.FatalOn(err)
+	case <-t.Timeout(20 * time.Second):
+		t.Fatal("plain watching didn't stop")
+	}
+	t.Contains(out.String(), "./fail_test.go:5:")
+	t.True(strings.HasSuffix(out.String(),
+		"\npkgs/suites: 2/0; tests: 2/1\n"))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func (s *PlainWatching) Stops_streaming_on_write_error(t *T) {
+	returned := &atomic.Bool{}
+	i := &InitFactories{Watcher: fxHeadless(t, false)}
+	i.watch = func(
+		diffs <-chan *model.PackagesDiff, mdl *modelState, _ chan bool,
+	) {
+		watch(diffs, mdl, nil)
+		returned.Store(true)
+	}
+
+	err := Plain(i, failingWriter{}, false)
+
+	t.ErrMatched(err, "write failed")
+	t.True(returned.Load())
+}
+
+func TestPlainWatching(t *testing.T) {
+	t.Parallel()
+	Run(&PlainWatching{}, t)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+pool.go limits the number of concurrently running package tests.  Test
+runs exceeding the limit are queued and started by their priority:
+currently failing packages first, then the most recently modified
+packages.
+*/
+
+package controller
+
+import (
+	"container/heap"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// job is a queued test run of a package.
+type job struct {
+
+	// failing is true if the package's tests are currently failing.
+	failing bool
+
+	// modTime is the package's modification time.
+	modTime time.Time
+
+	// seq keeps the submission order of jobs with the same priority.
+	seq int
+
+	run func()
+}
+
+// jobs implements heap.Interface whereas the job with the highest
+// priority is the least job.
+type jobs []*job
+
+func (jj jobs) Len() int { return len(jj) }
+
+func (jj jobs) Less(i, j int) bool {
+	if jj[i].failing != jj[j].failing {
+		return jj[i].failing
+	}
+	if !jj[i].modTime.Equal(jj[j].modTime) {
+		return jj[i].modTime.After(jj[j].modTime)
+	}
+	return jj[i].seq < jj[j].seq
+}
+
+func (jj jobs) Swap(i, j int) { jj[i], jj[j] = jj[j], jj[i] }
+
+func (jj *jobs) Push(x interface{}) { *jj = append(*jj, x.(*job)) }
+
+func (jj *jobs) Pop() interface{} {
+	old := *jj
+	j := old[len(old)-1]
+	*jj = old[:len(old)-1]
+	return j
+}
+
+// pool runs submitted jobs with at most a given number of workers.
+type pool struct {
+	*sync.Mutex
+	workers, running, seq int
+	queue                 jobs
+
+	// queued is called back with the queue length if it changes.
+	queued func(int)
+}
+
+// newPool returns a pool running at most given number of jobs
+// concurrently; GOMAXPROCS many if workers is not positive.  Given
+// callback is informed about changes of the queue length.
+func newPool(workers int, queued func(int)) *pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if queued == nil {
+		queued = func(int) {}
+	}
+	return &pool{Mutex: &sync.Mutex{}, workers: workers, queued: queued}
+}
+
+// submit starts given job if a worker is available otherwise the job is
+// queued.
+func (p *pool) submit(j *job) {
+	p.Lock()
+	defer p.Unlock()
+	p.seq++
+	j.seq = p.seq
+	if p.running < p.workers {
+		p.running++
+		go p.work(j)
+		return
+	}
+	heap.Push(&p.queue, j)
+	p.queued(len(p.queue))
+}
+
+// work executes given job and then the queued jobs by priority until
+// the queue is empty.
+func (p *pool) work(j *job) {
+	for j != nil {
+		j.run()
+		j = p.next()
+	}
+}
+
+func (p *pool) next() *job {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.queue) == 0 {
+		p.running--
+		return nil
+	}
+	j := heap.Pop(&p.queue).(*job)
+	p.queued(len(p.queue))
+	return j
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package controller
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/cmd/gounit/view"
+)
+
+type Pool struct{ Suite }
+
+func (s *Pool) SetUp(t *T) { t.Parallel() }
+
+func (s *Pool) Defaults_to_GOMAXPROCS_workers(t *T) {
+	t.True(newPool(0, nil).workers > 0)
+}
+
+func (s *Pool) Runs_at_most_given_number_of_jobs_concurrently(t *T) {
+	p, mutex, running, max := newPool(2, nil), &sync.Mutex{}, 0, 0
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		p.submit(&job{run: func() {
+			defer wg.Done()
+			mutex.Lock()
+			running++
+			if running > max {
+				max = running
+			}
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		}})
+	}
+	wg.Wait()
+	t.Eq(2, max)
+}
+
+func (s *Pool) Starts_failing_then_most_recently_modified_jobs(t *T) {
+	qq, started := []int{}, []string{}
+	p, block, done := newPool(1, func(n int) { qq = append(qq, n) }),
+		make(chan struct{}), make(chan struct{})
+	p.submit(&job{run: func() { <-block }})
+	now, wg := time.Now(), &sync.WaitGroup{}
+	submit := func(name string, failing bool, modTime time.Time) {
+		wg.Add(1)
+		p.submit(&job{failing: failing, modTime: modTime, run: func() {
+			started = append(started, name)
+			wg.Done()
+		}})
+	}
+	submit("old", false, now.Add(-time.Hour))
+	submit("new", false, now)
+	submit("failing", true, now.Add(-2*time.Hour))
+	submit("new-too", false, now)
+
+	close(block)
+	go func() { wg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-t.Timeout(time.Second):
+		t.Fatal("pool: jobs not executed")
+	}
+	t.Eq([]string{"failing", "new", "new-too", "old"}, started)
+	t.Eq([]int{1, 2, 3, 4, 3, 2, 1, 0}, qq)
+}
+
+func (s *Pool) Queue_length_is_reported_in_the_status(t *T) {
+	var status *view.Statuser
+	mdl := &modelState{Mutex: &sync.Mutex{}, state: &state{
+		view: []interface{}{&report{}, &view.Statuser{Tests: 5}},
+	}, viewUpdater: func(dd ...interface{}) {
+		status = dd[0].(*view.Statuser)
+	}}
+
+	mdl.updateQueued(3)
+
+	t.FatalIfNot(t.True(status != nil))
+	t.Eq(3, status.Queued)
+	t.Eq(5, status.Tests)
+	t.Eq(3, mdl.queued)
+}
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+	Run(&Pool{}, t)
+}
@@ -457,7 +457,7 @@ func reportSuite(
 		ll = append(ll, blankLine)
 	}
 	s.ForTest(func(t *model.Test) {
-		ll, llMask = reportSubTestLine(p, rr.OfTest(t), indent, ll, llMask)
+		ll, llMask = reportSubTestLine(p, rr.Of(t.Name()), indent, ll, llMask)
 	})
 	if len(rr.FinalizeOut) > 0 {
 		ll = append(ll, indent+"finalize-log:")
@@ -583,8 +583,11 @@ func reportSubTestLine(
 	ll = append(ll, i+r.String())
 	idx := uint(len(ll) - 1)
 	llMask[idx] = view.TestLine
-	if !r.Passed {
+	switch {
+	case !r.Passed:
 		llMask[idx] |= view.Failed
+	case r.Flaky:
+		llMask[idx] |= view.Flaky
 	}
 	ll, llMask = reportOutput(p, r.Output, i+indent, ll, llMask)
 	if r.HasSubs() {
@@ -780,8 +783,8 @@ func pkgFileLoc(p *pkg, s string) (loc string, n int, ok bool) {
 }
 
 type statusCount struct {
-	ssLen, ttLen, ffLen int
-	cf, tf, cl, tl, dl  int
+	ssLen, ttLen, ffLen, flLen int
+	cf, tf, cl, tl, dl         int
 }
 
 // newStatus calculates the number for the view's status-bar which at
@@ -795,7 +798,7 @@ type statusCount struct {
 // status calculation may return false event though stats are turned on.
 func newStatus(pp pkgs, om onMask) *view.Statuser {
 	// count suites, tests and failed tests
-	ssLen, ttLen, ffLen := 0, 0, 0
+	ssLen, ttLen, ffLen, flLen := 0, 0, 0, 0
 	cf, tf, cl, tl, dl := 0, 0, 0, 0, 0
 	n, rslt, hasErr := 0, make(chan *statusCount), false
 	sourceStats := om&statsOn == statsOn
@@ -806,7 +809,8 @@ func newStatus(pp pkgs, om onMask) *view.Statuser {
 			if p.HasErr() && !hasErr {
 				hasErr = true
 			}
-			sc := statusCount{ssLen: s, ttLen: n, ffLen: f}
+			sc := statusCount{
+				ssLen: s, ttLen: n, ffLen: f, flLen: p.flaky()}
 			if srcStt {
 				ss := p.SrcStats()
 				sc.cf = ss.Files
@@ -823,6 +827,7 @@ func newStatus(pp pkgs, om onMask) *view.Statuser {
 		ssLen += sc.ssLen
 		ttLen += sc.ttLen
 		ffLen += sc.ffLen
+		flLen += sc.flLen
 		if sourceStats {
 			cf += sc.cf
 			tf += sc.tf
@@ -837,6 +842,7 @@ func newStatus(pp pkgs, om onMask) *view.Statuser {
 		Suites:    ssLen,
 		Tests:     ttLen,
 		Failed:    ffLen,
+		Flaky:     flLen,
 		Files:     cf,
 		TestFiles: tf,
 		Lines:     cl,
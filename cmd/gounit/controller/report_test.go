@@ -250,7 +250,7 @@ func (s *Report) Updated_package_vetted_if_vet_is_turned_on(t *T) {
 	t.Contains(tt.ButtonBarCells().String(), "[v]et=on")
 	t.Not.Contains(tt.ReportCells().Trimmed(), "FAIL")
 
-	tt.beforeWatch(func() { tt.golden.Touch("vet") })
+	tt.beforeWatch(func() { fxEdit(tt.golden, "vet/src_test.go") })
 	t.Contains(tt.ReportCells().Trimmed(), "FAIL")
 	t.Contains(tt.ReportCells().Trimmed(), "./src.go:11:26")
 }
@@ -262,7 +262,7 @@ func (s *Report) Current_package_not_vetted_if_vet_is_turned_off(t *T) {
 	t.Contains(tt.ReportCells().Trimmed(), "FAIL")
 
 	tt.ClickButton("vet=on")
-	tt.beforeWatch(func() { tt.golden.Touch("vet") })
+	tt.beforeWatch(func() { fxEdit(tt.golden, "vet/src_test.go") })
 	t.Not.Contains(tt.ReportCells().Trimmed(), "FAIL")
 }
 
@@ -302,7 +302,7 @@ func (s *Report) Race_in_updated_package_if_race_is_turned_on(t *T) {
 	t.Contains(tt.ButtonBarCells(), "[r]ace=on")
 	t.Not.Contains(tt.ReportCells().Trimmed(), "WARNING: DATA RACE")
 
-	tt.beforeWatch(func() { tt.golden.Touch("race") })
+	tt.beforeWatch(func() { fxEdit(tt.golden, "race/src_test.go") })
 	// tt.beforeView(func() { tt.ClickReporting(2) })
 	t.Contains(tt.ReportCells().Trimmed(), "WARNING: DATA RACE")
 }
@@ -315,7 +315,7 @@ func (s *Report) No_race_if_current_package_race_is_turned_off(t *T) {
 	t.Contains(tt.ReportCells().Trimmed(), "WARNING: DATA RACE")
 
 	tt.ClickButton("race=on")
-	tt.beforeWatch(func() { tt.golden.Touch("race") })
+	tt.beforeWatch(func() { fxEdit(tt.golden, "race/src_test.go") })
 	t.Not.Contains(tt.ReportCells().Trimmed(), "WARNING: DATA RACE")
 }
 
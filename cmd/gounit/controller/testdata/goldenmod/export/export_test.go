@@ -0,0 +1,31 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+)
+
+func TestGo(t *testing.T) {}
+
+func TestGoSubs(t *testing.T) {
+	t.Run("b_sub", func(t *testing.T) {})
+	t.Run("a_sub", func(t *testing.T) { t.Log("sub log") })
+}
+
+type Ordered struct{ Suite }
+
+func (s *Ordered) Init(t *S) { t.Log("init log") }
+
+func (s *Ordered) Written_first(t *T) { time.Sleep(5 * time.Millisecond) }
+
+func (s *Ordered) Has_nested_sub_tests(t *T) {
+	t.GoT().Run("nested", func(t *testing.T) { t.Log("nested log") })
+}
+
+func (s *Ordered) A_written_last(t *T) {}
+
+func (s *Ordered) Finalize(t *S) { t.Log("finalize log") }
+
+func TestOrdered(t *testing.T) { Run(&Ordered{}, t) }
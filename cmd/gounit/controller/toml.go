@@ -0,0 +1,381 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+toml.go decodes the subset of TOML which is needed for gounit's
+configuration file: comments, bare or quoted keys, basic and literal
+strings, integers, floats, booleans, (multi-line) arrays, tables and
+arrays of tables.  Dotted keys, inline tables, multi-line strings and
+dates are not supported.  Supported values are decoded according to
+the TOML v1.0.0 specification, i.e. an input which isn't valid TOML is
+rejected.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlDecoder decodes a TOML document into maps of the form the json
+// package decodes a json object into.
+type tomlDecoder struct {
+	s    string
+	pos  int
+	line int
+}
+
+// decodeTOML decodes given TOML document into a map.  Returned error
+// reports the line of the first syntax error.
+func decodeTOML(content []byte) (map[string]interface{}, error) {
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("toml: invalid UTF-8 encoding")
+	}
+	d := &tomlDecoder{s: string(content), line: 1}
+	root := map[string]interface{}{}
+	table := root
+	for {
+		d.skip(true)
+		if d.eof() {
+			return root, nil
+		}
+		var err error
+		switch {
+		case strings.HasPrefix(d.s[d.pos:], "[["):
+			table, err = d.arrayTable(root)
+		case d.s[d.pos] == '[':
+			table, err = d.table(root)
+		default:
+			err = d.keyValue(table)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("toml: line %d: %v", d.line, err)
+		}
+	}
+}
+
+func (d *tomlDecoder) eof() bool { return d.pos >= len(d.s) }
+
+// skip skips white space and comments; line breaks are skipped iff nl
+// is true.
+func (d *tomlDecoder) skip(nl bool) {
+	for !d.eof() {
+		switch d.s[d.pos] {
+		case ' ', '\t', '\r':
+			d.pos++
+		case '\n':
+			if !nl {
+				return
+			}
+			d.line++
+			d.pos++
+		case '#':
+			for !d.eof() && d.s[d.pos] != '\n' {
+				d.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine consumes the rest of a line which may only contain white
+// space and a comment.
+func (d *tomlDecoder) endOfLine() error {
+	d.skip(false)
+	if d.eof() {
+		return nil
+	}
+	if d.s[d.pos] != '\n' {
+		return fmt.Errorf("unexpected %q", d.s[d.pos])
+	}
+	return nil
+}
+
+// header returns the name of the table header ending with given
+// closing brackets.
+func (d *tomlDecoder) header(closing string) (string, error) {
+	end := strings.Index(d.s[d.pos:], closing)
+	if end < 0 || strings.Contains(d.s[d.pos:d.pos+end], "\n") {
+		return "", fmt.Errorf("unterminated table header")
+	}
+	name := strings.TrimSpace(d.s[d.pos : d.pos+end])
+	d.pos += end + len(closing)
+	if !isBareKey(name) {
+		return "", fmt.Errorf("unsupported table name %q", name)
+	}
+	return name, d.endOfLine()
+}
+
+// table starts a new table in given root table.
+func (d *tomlDecoder) table(root map[string]interface{}) (
+	map[string]interface{}, error,
+) {
+	d.pos++
+	name, err := d.header("]")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := root[name]; ok {
+		return nil, fmt.Errorf("duplicate table %q", name)
+	}
+	table := map[string]interface{}{}
+	root[name] = table
+	return table, nil
+}
+
+// arrayTable appends a new table to an array of tables in given root
+// table.
+func (d *tomlDecoder) arrayTable(root map[string]interface{}) (
+	map[string]interface{}, error,
+) {
+	d.pos += 2
+	name, err := d.header("]]")
+	if err != nil {
+		return nil, err
+	}
+	table := map[string]interface{}{}
+	switch tt := root[name].(type) {
+	case nil:
+		root[name] = []interface{}{table}
+	case []interface{}:
+		root[name] = append(tt, table)
+	default:
+		return nil, fmt.Errorf("%q is not an array of tables", name)
+	}
+	return table, nil
+}
+
+// keyValue decodes a key value pair into given table.
+func (d *tomlDecoder) keyValue(table map[string]interface{}) error {
+	key, err := d.key()
+	if err != nil {
+		return err
+	}
+	d.skip(false)
+	if d.eof() || d.s[d.pos] != '=' {
+		return fmt.Errorf("expected '=' after key %q", key)
+	}
+	d.pos++
+	d.skip(false)
+	v, err := d.value()
+	if err != nil {
+		return err
+	}
+	if _, ok := table[key]; ok {
+		return fmt.Errorf("duplicate key %q", key)
+	}
+	table[key] = v
+	return d.endOfLine()
+}
+
+func (d *tomlDecoder) key() (string, error) {
+	if d.s[d.pos] == '"' || d.s[d.pos] == '\'' {
+		return d.string()
+	}
+	start := d.pos
+	for !d.eof() && isBareKeyByte(d.s[d.pos]) {
+		d.pos++
+	}
+	if start == d.pos {
+		return "", fmt.Errorf("unexpected %q", d.s[d.pos])
+	}
+	if !d.eof() && d.s[d.pos] == '.' {
+		return "", fmt.Errorf("dotted keys are not supported")
+	}
+	return d.s[start:d.pos], nil
+}
+
+func (d *tomlDecoder) value() (interface{}, error) {
+	if d.eof() {
+		return nil, fmt.Errorf("missing value")
+	}
+	switch d.s[d.pos] {
+	case '"', '\'':
+		return d.string()
+	case '[':
+		return d.array()
+	case '{':
+		return nil, fmt.Errorf("inline tables are not supported")
+	}
+	start := d.pos
+	for !d.eof() && !strings.ContainsRune(" \t\r\n,]#", rune(d.s[d.pos])) {
+		d.pos++
+	}
+	token := d.s[start:d.pos]
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return number(token)
+}
+
+var (
+	reTOMLInt = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	reTOMLHex = regexp.MustCompile(`^0x[0-9A-Fa-f](_?[0-9A-Fa-f])*$`)
+	reTOMLOct = regexp.MustCompile(`^0o[0-7](_?[0-7])*$`)
+	reTOMLBin = regexp.MustCompile(`^0b[01](_?[01])*$`)
+
+	reTOMLFloat = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)` +
+		`((\.[0-9](_?[0-9])*)([eE][+-]?[0-9](_?[0-9])*)?|` +
+		`[eE][+-]?[0-9](_?[0-9])*)$`)
+)
+
+// number decodes given token as TOML integer or float.  Note that
+// TOML's integers are decimal unless they have a 0x, 0o or 0b prefix
+// and that underscores must be surrounded by digits.
+func number(token string) (interface{}, error) {
+	base, digits := 0, token
+	switch {
+	case reTOMLInt.MatchString(token):
+		base = 10
+	case reTOMLHex.MatchString(token):
+		base, digits = 16, token[2:]
+	case reTOMLOct.MatchString(token):
+		base, digits = 8, token[2:]
+	case reTOMLBin.MatchString(token):
+		base, digits = 2, token[2:]
+	case reTOMLFloat.MatchString(token):
+		f, err := strconv.ParseFloat(
+			strings.ReplaceAll(token, "_", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q", token)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("invalid value %q", token)
+	}
+	i, err := strconv.ParseInt(
+		strings.ReplaceAll(digits, "_", ""), base, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid integer %q", token)
+	}
+	return i, nil
+}
+
+// string decodes a basic or literal single line string.  A literal
+// string is taken as is while a basic string's escape sequences are
+// replaced by the characters they represent.
+func (d *tomlDecoder) string() (string, error) {
+	quote := d.s[d.pos]
+	d.pos++
+	b := strings.Builder{}
+	for !d.eof() && d.s[d.pos] != quote {
+		c := d.s[d.pos]
+		if c == '\n' {
+			break
+		}
+		if c < 0x20 && c != '\t' || c == 0x7f {
+			return "", fmt.Errorf("control character %#x in string", c)
+		}
+		if quote == '\'' || c != '\\' {
+			b.WriteByte(c)
+			d.pos++
+			continue
+		}
+		if err := d.escape(&b); err != nil {
+			return "", err
+		}
+	}
+	if d.eof() || d.s[d.pos] != quote {
+		return "", fmt.Errorf("unterminated string")
+	}
+	d.pos++
+	return b.String(), nil
+}
+
+var tomlEscapes = map[byte]byte{
+	'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r',
+	'"': '"', '\\': '\\',
+}
+
+// escape writes the character represented by the escape sequence at
+// the decoder's position to given builder.
+func (d *tomlDecoder) escape(b *strings.Builder) error {
+	d.pos++
+	if d.eof() {
+		return fmt.Errorf("unterminated string")
+	}
+	if c, ok := tomlEscapes[d.s[d.pos]]; ok {
+		b.WriteByte(c)
+		d.pos++
+		return nil
+	}
+	n := 0
+	switch d.s[d.pos] {
+	case 'u':
+		n = 4
+	case 'U':
+		n = 8
+	default:
+		return fmt.Errorf("invalid escape sequence \\%c", d.s[d.pos])
+	}
+	d.pos++
+	if d.pos+n > len(d.s) {
+		return fmt.Errorf("invalid unicode escape sequence")
+	}
+	hex := d.s[d.pos : d.pos+n]
+	r, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(r)) {
+		return fmt.Errorf("invalid unicode escape sequence %q", hex)
+	}
+	b.WriteRune(rune(r))
+	d.pos += n
+	return nil
+}
+
+// array decodes an array whose values may span several lines.
+func (d *tomlDecoder) array() ([]interface{}, error) {
+	d.pos++
+	vv := []interface{}{}
+	for {
+		d.skip(true)
+		if d.eof() {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		if d.s[d.pos] == ']' {
+			d.pos++
+			return vv, nil
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		vv = append(vv, v)
+		d.skip(true)
+		if d.eof() {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		switch d.s[d.pos] {
+		case ',':
+			d.pos++
+		case ']':
+		default:
+			return nil, fmt.Errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func isBareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isBareKeyByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isBareKeyByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' ||
+		b >= '0' && b <= '9' || b == '_' || b == '-'
+}
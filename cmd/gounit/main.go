@@ -1,19 +1,98 @@
 /*
 Gounit watches package directories of a go module and reports test
-results on source file changes.  It watches always the current working
-directory and its nested packages.  It fails to do so if the current
-directory is not inside an module.
+results on source file changes.  It watches the current working
+directory or the directory given by the -dir flag and its nested
+packages.  It fails to do so if the watched directory is not inside a
+module.  Gounit is configured by the first .gounit.toml or .gounit.json
+file found ascending from the watched directory to its module's root
+(see controller.Config) whose settings are overridden by given flags.
+Arguments after -- are passed through to go test.
 
 Usage:
 
-	gounit
+	gounit [flags] [-- go test flags]
+
+The flags are:
+
+	-dir directory
+	    watched directory; defaults to the working directory
+	-timeout duration
+	    duration after which a package's test run is canceled
+	-interval duration
+	    duration between two checks for changes
+	-ignore names
+	    comma separated names of additionally ignored directories
+	-vet, -race, -stats
+	    switch vet, race or stats initially on
+	-run regexp
+	    run only tests and suites matching given regexp
+	-once
+	    run all tests once, report them to stdout and exit with
+	    status 1 if a test fails
+	-plain
+	    watch without terminal ui and write each report and status
+	    line as plain text to stdout, e.g. in editor terminals
+	-export format
+	    run all tests once, write their results as json or junit
+	    (JUnit XML) to stdout and exit with status 1 if a test fails
+	-color
+	    color reports of -once and -plain with ANSI escape sequences
+
+E.g.:
+
+	gounit -dir ./pkg/tfs -race -run 'Suite' -- -count=1
+	gounit -once -color -vet
+	gounit -plain
+	gounit -export=junit > report.xml
 */
 package main
 
 import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+
 	"github.com/slukits/gounit/cmd/gounit/controller"
 )
 
 func main() {
-	controller.New(nil)
+	flags, err := controller.ParseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if flags.Once {
+		passed, err := controller.Once(&controller.InitFactories{
+			Config: flags.Config}, os.Stdout, flags.Color)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !passed {
+			os.Exit(1)
+		}
+		return
+	}
+	if flags.Export != "" {
+		passed, err := controller.Export(&controller.InitFactories{
+			Config: flags.Config}, os.Stdout, flags.Export)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !passed {
+			os.Exit(1)
+		}
+		return
+	}
+	if flags.Plain {
+		err := controller.Plain(&controller.InitFactories{
+			Config: flags.Config}, os.Stdout, flags.Color)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	controller.New(&controller.InitFactories{Config: flags.Config})
 }
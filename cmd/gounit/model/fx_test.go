@@ -293,12 +293,12 @@ func fixturePkgTmpDir(
 	tmp := t.FS().Tmp()
 	testData.Child(testDataDir).Copy(tmp)
 	pkgStats, ok := newTestingPackageStat(
-		tmp.Child(testDataDir).Path())
+		tmp.Child(testDataDir).Path(), nil)
 	if !ok {
 		t.Fatalf("failed to obtain package stats of %s",
 			tmp.Child(testDataDir).Path())
 	}
-	tt, err := pkgStats.loadTestFiles()
+	tt, err := pkgStats.loadTestFiles(nil)
 	t.FatalOn(err)
 	return &TestingPackage{abs: pkgStats.abs, files: tt}, tmp
 }
@@ -0,0 +1,104 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+inputs.go determines a package's inputs, i.e. files which are not the
+package's go source files but whose changes are considered a change of
+the package: files embedded by go:embed directives, the files of the
+package's testdata directory and the files matching the glob patterns
+of Sources.Inputs.
+*/
+
+package model
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// embedDirective prefixes the patterns of a go:embed directive.
+const embedDirective = "//go:embed"
+
+// parseEmbeds returns the patterns of given go source's go:embed
+// directives.  NOTE quoted patterns containing white space are not
+// supported.
+func parseEmbeds(content []byte) (pp []string) {
+	if !bytes.Contains(content, []byte(embedDirective)) {
+		return nil
+	}
+	for _, l := range bytes.Split(content, []byte("\n")) {
+		l = bytes.TrimSpace(l)
+		if !bytes.HasPrefix(l, []byte(embedDirective+" ")) &&
+			!bytes.HasPrefix(l, []byte(embedDirective+"\t")) {
+			continue
+		}
+		for _, p := range strings.Fields(string(l[len(embedDirective):])) {
+			if uq, err := strconv.Unquote(p); err == nil {
+				p = uq
+			}
+			pp = append(pp, p)
+		}
+	}
+	return pp
+}
+
+// input is a file of a package which is not one of its go source files.
+type input struct {
+
+	// rel is the input's path relative to its package's directory.
+	rel string
+	fi  os.FileInfo
+}
+
+// packageInputs returns the files of given package directory which
+// match given patterns ordered by their package relative paths.  A
+// matching directory contributes all files of its directory tree.  The
+// files of the package's testdata directory are included iff testdata
+// is true.  The package's go source files are never included.
+func packageInputs(dir string, patterns []string, testdata bool) []input {
+	ii, seen := []input{}, map[string]bool{}
+	add := func(path string) {
+		filepath.WalkDir(path, func(
+			p string, d fs.DirEntry, err error,
+		) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil || seen[rel] {
+				return nil
+			}
+			if filepath.Dir(rel) == "." && strings.HasSuffix(rel, ".go") {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			seen[rel] = true
+			ii = append(ii, input{rel: rel, fi: fi})
+			return nil
+		})
+	}
+	if testdata {
+		add(filepath.Join(dir, "testdata"))
+	}
+	for _, p := range patterns {
+		mm, err := filepath.Glob(
+			filepath.Join(dir, strings.TrimPrefix(p, "all:")))
+		if err != nil {
+			continue
+		}
+		for _, m := range mm {
+			add(m)
+		}
+	}
+	sort.Slice(ii, func(i, j int) bool { return ii[i].rel < ii[j].rel })
+	return ii
+}
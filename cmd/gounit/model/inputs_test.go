@@ -0,0 +1,53 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type Inputs struct{ Suite }
+
+func (s *Inputs) SetUp(t *T) { t.Parallel() }
+
+func (s *Inputs) Are_parsed_from_go_embed_directives(t *T) {
+	src := []byte("package p\n\nimport _ \"embed\"\n\n" +
+		"//go:embed a.txt \"b.txt\" `d/*.json`\n" +
+		"var a string\n\n// go:embed ignored.txt\n" +
+		"\t//go:embed\tall:assets\nvar b string\n")
+
+	t.Eq([]string{"a.txt", "b.txt", "d/*.json", "all:assets"},
+		parseEmbeds(src))
+}
+
+func (s *Inputs) Include_testdata_and_matched_files_but_go_sources(
+	t *T,
+) {
+	dir := t.FS().Tmp()
+	dir.MkFile("p.go", []byte("package p\n"))
+	dir.MkFile("q.sql", []byte("select 1;\n"))
+	dir.MkFile("r.txt", []byte("r\n"))
+	testdata, _ := dir.Mk("testdata")
+	testdata.MkFile("golden.txt", []byte("golden\n"))
+	nested, _ := dir.Mk("assets", "nested")
+	nested.MkFile("a.json", []byte("{}\n"))
+
+	got := []string{}
+	for _, i := range packageInputs(
+		dir.Path(), []string{"*.sql", "*.go", "all:assets"}, true) {
+
+		got = append(got, i.rel)
+	}
+
+	t.Eq([]string{"assets/nested/a.json", "q.sql", "testdata/golden.txt"},
+		got)
+}
+
+func TestInputs(t *testing.T) {
+	t.Parallel()
+	Run(&Inputs{}, t)
+}
@@ -104,6 +104,11 @@ type Sources struct {
 	// greater zero; the later if it is zero.
 	quit chan uint64
 
+	// profile channels profile switches to the diff-reporting go
+	// routine.  Its 1-buffer holds the most recent switch which hasn't
+	// been received yet.
+	profile chan *Profile
+
 	isWatched chan bool
 
 	// newID creates a new Module-instance unique ID > 0 for registered
@@ -124,6 +129,11 @@ type Sources struct {
 	// watcher.
 	Interval time.Duration
 
+	// Backend selects how changes of watched sources are detected.  It
+	// defaults to file system notifications where available falling
+	// back to polling every Interval, see [AutoBackend].
+	Backend Backend
+
 	// Timeout is the duration after which a testing package's tests run
 	// is canceled.
 	Timeout time.Duration
@@ -135,6 +145,21 @@ type Sources struct {
 	// of Ignore are not taken into account (until Sources.QuitAll was
 	// called and then Sources.Watch again).
 	Ignore []string
+
+	// Inputs are glob patterns relative to a package's directory
+	// matching files which are not go source files but whose changes
+	// should be reported as changes of the package, e.g. "*.sql" or
+	// "fixtures".  A matching directory matches all files of its tree.
+	// Independently of Inputs the files matched by a package's go:embed
+	// directives and the files of its testdata directory are inputs.
+	// Like Ignore Inputs must be set before the first call of Watch.
+	Inputs []string
+
+	// Profile determines the test files which are considered for the
+	// discovery of testing packages and the settings of their test runs
+	// at the first call of Watch.  It defaults to the DefaultProfile.
+	// Use [Sources.SetProfile] to switch profiles while watching.
+	Profile *Profile
 }
 
 // ModuleName returns a watched module's name.  Note if [Sources.Watch]
@@ -172,7 +197,9 @@ func (m *Sources) ModuleDir() string {
 // about is the first module which is found in Sources.Dir ascending
 // towards root.  If Module.Dir is unset the current working directory
 // is used.  If no directory with a go.mod file is found a wrapped
-// ErrNoModule error is returned.  I.e. after this method's first call
+// ErrNoModule error is returned.  If Sources.Backend is NotifyBackend
+// and file system notifications are not available a wrapped ErrNotify
+// error is returned.  I.e. after this method's first call
 // Sources.Dir is the found module directory and [Sources.Name] provides
 // its name.  Returned ID may be used to unregister the watcher with
 // given ID, see [Sources.Quit].  If a watcher is unregistered its diff
@@ -188,7 +215,9 @@ func (m *Sources) Watch() (diff <-chan *PackagesDiff, ID uint64, err error) {
 	if len(m.Ignore) == 0 {
 		m.Ignore = DefaultIgnore
 	}
-	m.ensureDiffer() // go routine reporting diffs
+	if err := m.ensureDiffer(); err != nil { // go routine reporting diffs
+		return nil, 0, err
+	}
 
 	ID, _diff := m.newID(), make(chan *PackagesDiff, 1)
 	m.register <- &newWatcher{
@@ -219,12 +248,23 @@ func (m *Sources) ensureNameAndDir() (err error) {
 	return nil
 }
 
-func (m *Sources) ensureDiffer() {
+func (m *Sources) ensureDiffer() error {
 	if m.register != nil {
-		return
+		return nil
+	}
+	ignore := ignoreClosure(m.Ignore...)
+	var ntf *notifier
+	if m.Backend != PollBackend {
+		n, err := newNotifier(
+			m.Dir, ignoreClosure(inputsIgnore(m.Ignore)...))
+		if err != nil && m.Backend == NotifyBackend {
+			return err
+		}
+		ntf = n
 	}
 	m.register = make(chan *newWatcher)
 	m.quit = make(chan uint64)
+	m.profile = make(chan *Profile, 1)
 	m.newID = idClosure()
 	if m.Interval == 0 {
 		m.Interval = DefaultInterval
@@ -232,8 +272,30 @@ func (m *Sources) ensureDiffer() {
 	if m.Timeout == 0 {
 		m.Timeout = DefaultTimeout
 	}
-	m.isWatched = differ(m.moduleDir, m.Dir, m.Interval, m.Timeout,
-		ignoreClosure(m.Ignore...), m.register, m.quit)
+	m.isWatched = differ(&statsConfig{
+		moduleDir: m.moduleDir, module: m.name, dir: m.Dir,
+		ignore: ignore, inputs: m.Inputs, profile: m.Profile,
+	}, m.Interval, m.Timeout, ntf, m.register, m.quit, m.profile)
+	return nil
+}
+
+// SetProfile switches the profile of watched sources to given profile.
+// Since a profile is part of a package's content hash all testing
+// packages are reported again to watchers.  SetProfile doesn't block
+// while watched sources are diffed; a switch which hasn't been
+// received yet is replaced by given profile.
+func (m *Sources) SetProfile(p *Profile) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Profile = p
+	if m.profile == nil {
+		return
+	}
+	select {
+	case <-m.profile:
+	default:
+	}
+	m.profile <- p
 }
 
 // IsWatched returns true iff at least one watcher is registered.  Note
@@ -272,6 +334,7 @@ func (m *Sources) QuitAll() {
 	close(m.quit)
 	m.register = nil
 	m.quit = nil
+	m.profile = nil
 	m.isWatched = nil
 }
 
@@ -283,6 +346,20 @@ func idClosure() func() uint64 {
 	}
 }
 
+// inputsIgnore returns given ignored directories without testdata
+// directories whose files are package inputs, i.e. their changes must be
+// noticed.
+func inputsIgnore(ii []string) []string {
+	_ii := []string{}
+	for _, i := range ii {
+		if i == "testdata" {
+			continue
+		}
+		_ii = append(_ii, i)
+	}
+	return _ii
+}
+
 func ignoreClosure(ii ...string) func(string) bool {
 	return func(s string) bool {
 		for _, i := range ii {
@@ -306,21 +383,30 @@ type watcher struct {
 }
 
 // differ starts a go routine which every given interval informs all
-// registered watchers about changes of testing packages in given
-// directory (i.e. go module).  This go routine also listens on the
-// register and quit channel to add a new watcher respectively remove
-// one or all.  The later happens if the zero value is received over the
-// quit channel.  NOTE the provided diff channel of a new watcher must
-// be buffered with the capacity 1!  In this case the go routine can
-// guarantee to not block and keep each watcher individually accurately
-// posted about changes independently if a watcher is polling from its
-// diff-channel or not.
-func differ(moduleDir string, dir string,
-	interval, timeout time.Duration,
-	ignore func(string) bool,
+// registered watchers about changes of testing packages in the
+// directory of given configuration.  If a notifier is given the
+// packages' stats are only calculated if a watcher was registered, the
+// profile was switched or the notifier signaled a change since the last
+// calculation.  This go routine also listens on the register and quit
+// channel to add a new watcher respectively remove one or all.  The
+// later happens if the zero value is received over the quit channel.
+// A profile received over the profile channel replaces the
+// configuration's profile.  NOTE the provided diff
+// channel of a new watcher must be buffered with the capacity 1!  In
+// this case the go routine can guarantee to not block and keep each
+// watcher individually accurately posted about changes independently
+// if a watcher is polling from its diff-channel or not.
+func differ(cfg *statsConfig,
+	interval, timeout time.Duration, ntf *notifier,
 	register chan *newWatcher, quit <-chan uint64,
+	profile <-chan *Profile,
 ) (isWatched chan bool) {
 	ww, isWatched := map[uint64]*watcher{}, make(chan bool)
+	var changed <-chan struct{} // nil channel blocks forever
+	if ntf != nil {
+		changed = ntf.changed
+	}
+	dirty, last := false, (*packagesStat)(nil)
 
 	go func() {
 		for {
@@ -329,13 +415,26 @@ func differ(moduleDir string, dir string,
 				isWatched <- len(ww) > 0
 			case register := <-register:
 				ww[register.ID] = &watcher{diff: register.diff}
+				dirty = true
 			case wID := <-quit:
 				if terminate := quitWatching(wID, ww); terminate {
+					if ntf != nil {
+						ntf.close()
+					}
 					return
 				}
+			case p := <-profile:
+				cfg.profile = p
+				dirty = true
+			case <-changed:
+				dirty = true
 			case <-time.After(interval):
-				reportDiffs(
-					calcPackagesStat(moduleDir, dir, ignore), ww, timeout)
+				if ntf != nil && !dirty {
+					continue
+				}
+				dirty = false
+				last = calcPackagesStat(cfg, last)
+				reportDiffs(last, ww, timeout)
 			}
 		}
 	}()
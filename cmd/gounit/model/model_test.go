@@ -5,10 +5,12 @@
 package model
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
 )
 
 func TestModuleDefaultsToWorkingDirectory(t *testing.T) {
@@ -315,6 +317,334 @@ func (s *source) Reports_deleted_package_to_registered_watcher(t *T) {
 	t.Eq(1, n)
 }
 
+func (s *source) Reports_added_package_with_poll_backend(t *T) {
+	fx := NewFX(t).Set(FxMod | FxTestingPackage)
+	fx.Interval = 1 * time.Millisecond
+	fx.Backend = PollBackend
+	defer fx.QuitAll()
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case <-diff:
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected initial diff-report")
+	}
+
+	fx.Set(FxPackage | FxTestingPackage)
+	select {
+	case add := <-diff:
+		t.True(add != nil)
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected diff-report for added package")
+	}
+}
+
+func (s *source) Doesnt_report_touched_package_without_changes(t *T) {
+	fx := NewFX(t).Set(FxMod | FxTestingPackage)
+	fx.Interval = 1 * time.Millisecond
+	defer fx.QuitAll()
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case <-diff:
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected initial diff-report")
+	}
+
+	pkg := fx.FxDir.Child(fx.testingPackageNameOf(1))
+	pkg.Touch(fxTestFileName + "_test.go")
+	pkg.WriteContent(fxTestFileName+".go", pkg.FileContent(
+		fxTestFileName+".go"))
+
+	select {
+	case <-diff:
+		t.Error("unexpected diff-report of unchanged package")
+	case <-t.Timeout(30 * time.Millisecond):
+	}
+}
+
+func (s *source) Reports_package_with_changed_content(t *T) {
+	fx := NewFX(t).Set(FxMod | FxTestingPackage)
+	fx.Interval = 1 * time.Millisecond
+	defer fx.QuitAll()
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case <-diff:
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected initial diff-report")
+	}
+
+	pkg := fx.FxDir.Child(fx.testingPackageNameOf(1))
+	pkg.WriteContent(fxTestFileName+".go", append(pkg.FileContent(
+		fxTestFileName+".go"), []byte("\nvar v = 42\n")...))
+
+	select {
+	case update := <-diff:
+		n := 0
+		update.For(func(tp *TestingPackage) (stop bool) {
+			n++
+			t.Eq(fx.testingPackageNameOf(1), tp.Name())
+			return false
+		})
+		t.Eq(1, n)
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected diff-report of changed package")
+	}
+}
+
+// fxImporters creates in given fixture the package "lib", the package
+// "mid" importing lib and the testing packages "user" importing mid
+// and "other".
+func fxImporters(fx *ModuleFX) {
+	lib, _ := fx.FxDir.Mk("lib")
+	lib.MkPkgFile("lib", []byte("func F() int { return 1 }\n"))
+	lib.MkPkgTest("lib", []byte(fxTest))
+	mid, _ := fx.FxDir.Mk("mid")
+	mid.MkPkgFile("mid", []byte(fmt.Sprintf("import \"%s/lib\"\n\n"+
+		"func F() int { return lib.F() }\n", FxModuleName)))
+	user, _ := fx.FxDir.Mk("user")
+	user.MkPkgTest("user", []byte(fmt.Sprintf("import (\n"+
+		"\t\"testing\"\n\n\t\"%s/mid\"\n)\n\n"+
+		"func TestUser(t *testing.T) { _ = mid.F() }\n", FxModuleName)))
+	other, _ := fx.FxDir.Mk("other")
+	other.MkPkgTest("other", []byte(fxTest))
+}
+
+func (s *source) Reports_importers_of_changed_package_as_affected(t *T) {
+	fx := NewFX(t).Set(FxMod)
+	fxImporters(fx)
+	fx.Interval = 1 * time.Millisecond
+	defer fx.QuitAll()
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case <-diff:
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected initial diff-report")
+	}
+
+	lib := fx.FxDir.Child("lib")
+	lib.WriteContent("lib.go", append(lib.FileContent("lib.go"),
+		[]byte("\nfunc G() {}\n")...))
+
+	select {
+	case update := <-diff:
+		got := map[string]bool{}
+		update.For(func(tp *TestingPackage) (stop bool) {
+			got[tp.ID()] = tp.Affected()
+			return false
+		})
+		t.Eq(map[string]bool{"lib": false, "user": true}, got)
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected diff-report of changed package")
+	}
+}
+
+func (s *source) Doesnt_report_importers_of_changed_test_file(t *T) {
+	fx := NewFX(t).Set(FxMod)
+	fxImporters(fx)
+	fx.Interval = 1 * time.Millisecond
+	defer fx.QuitAll()
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case <-diff:
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected initial diff-report")
+	}
+
+	lib := fx.FxDir.Child("lib")
+	lib.WriteContent("lib_test.go", append(lib.FileContent("lib_test.go"),
+		[]byte("\nfunc TestG(t *testing.T) {}\n")...))
+
+	select {
+	case update := <-diff:
+		got := map[string]bool{}
+		update.For(func(tp *TestingPackage) (stop bool) {
+			got[tp.ID()] = tp.Affected()
+			return false
+		})
+		t.Eq(map[string]bool{"lib": false}, got)
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected diff-report of changed package")
+	}
+}
+
+// fxWatchInputs creates a fixture with a testing package and given
+// input patterns, starts watching it and consumes the initial diff.
+func fxWatchInputs(t *T, inputs ...string) (
+	*ModuleFX, *tfs.Dir, <-chan *PackagesDiff,
+) {
+	fx := NewFX(t).Set(FxMod | FxTestingPackage)
+	fx.Interval = 1 * time.Millisecond
+	fx.Inputs = inputs
+	pkg := fx.FxDir.Child(fx.testingPackageNameOf(1))
+	pkg.MkPkgFile("embed", []byte("import _ \"embed\"\n\n"+
+		"//go:embed asset.txt\nvar asset string\n"))
+	pkg.MkFile("asset.txt", []byte("asset\n"))
+	pkg.MkFile("query.sql", []byte("select 1;\n"))
+	testdata, _ := pkg.Mk("testdata")
+	testdata.MkFile("golden.txt", []byte("golden\n"))
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case <-diff:
+	case <-t.Timeout(30 * time.Millisecond):
+		t.Fatal("expected initial diff-report")
+	}
+	return fx, pkg, diff
+}
+
+// isReported returns true iff given diff channel reports given
+// fixture's testing package within given duration.
+func isReported(
+	t *T, fx *ModuleFX, diff <-chan *PackagesDiff, d time.Duration,
+) bool {
+	select {
+	case update := <-diff:
+		n := 0
+		update.For(func(tp *TestingPackage) (stop bool) {
+			t.Eq(fx.testingPackageNameOf(1), tp.Name())
+			n++
+			return false
+		})
+		return n == 1
+	case <-t.Timeout(d):
+		return false
+	}
+}
+
+func (s *source) Reports_package_with_changed_testdata(t *T) {
+	fx, pkg, diff := fxWatchInputs(t)
+	defer fx.QuitAll()
+
+	pkg.WriteContent("testdata/golden.txt", []byte("updated\n"))
+
+	t.True(isReported(t, fx, diff, time.Second))
+}
+
+func (s *source) Reports_package_with_changed_embedded_file(t *T) {
+	fx, pkg, diff := fxWatchInputs(t)
+	defer fx.QuitAll()
+
+	pkg.WriteContent("asset.txt", []byte("updated\n"))
+
+	t.True(isReported(t, fx, diff, time.Second))
+}
+
+func (s *source) Reports_package_with_changed_input_file(t *T) {
+	fx, pkg, diff := fxWatchInputs(t, "*.sql")
+	defer fx.QuitAll()
+
+	pkg.WriteContent("query.sql", []byte("select 2;\n"))
+
+	t.True(isReported(t, fx, diff, time.Second))
+}
+
+func (s *source) Doesnt_report_package_with_changed_non_input(t *T) {
+	fx, pkg, diff := fxWatchInputs(t)
+	defer fx.QuitAll()
+
+	pkg.WriteContent("query.sql", []byte("select 2;\n"))
+
+	t.Not.True(isReported(t, fx, diff, 30*time.Millisecond))
+}
+
+const fxTagged = "//go:build integration\n\npackage tagged\n\n" +
+	"import \"testing\"\n\nfunc TestTagged(t *testing.T) {}\n"
+
+// reportedIDs returns the IDs of the testing packages of given diff.
+func reportedIDs(diff *PackagesDiff) map[string]*TestingPackage {
+	ids := map[string]*TestingPackage{}
+	diff.For(func(tp *TestingPackage) (stop bool) {
+		ids[tp.ID()] = tp
+		return false
+	})
+	return ids
+}
+
+func (s *source) Reports_tagged_package_only_with_matching_profile(
+	t *T,
+) {
+	fx := NewFX(t).Set(FxMod | FxTestingPackage)
+	fx.Interval = 1 * time.Millisecond
+	integration := &Profile{Name: "integration",
+		Tags: []string{"integration"}}
+	fx.Profile = integration
+	pkg, _ := fx.FxDir.Mk("tagged")
+	pkg.MkFile("tagged_test.go", []byte(fxTagged))
+	defer fx.QuitAll()
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case initial := <-diff:
+		tp, ok := reportedIDs(initial)["tagged"]
+		t.FatalIfNot(t.True(ok))
+		t.True(tp.Profile == integration)
+		t.Eq(1, tp.LenTests())
+	case <-t.Timeout(time.Second):
+		t.Fatal("expected initial diff-report")
+	}
+
+	fx.SetProfile(nil)
+
+	select {
+	case update := <-diff:
+		ids := reportedIDs(update)
+		_, ok := ids["tagged"]
+		t.Not.True(ok)
+		_, ok = ids[fx.testingPackageNameOf(1)]
+		t.True(ok)
+	case <-t.Timeout(time.Second):
+		t.Fatal("expected diff-report of profile switch")
+	}
+}
+
+func (s *source) Switches_profiles_while_diffing_is_busy(t *T) {
+	fx := NewFX(t).Set(FxMod | FxTestingPackage)
+	fx.Interval = 1 * time.Millisecond
+	pkg, _ := fx.FxDir.Mk("tagged")
+	pkg.MkFile("tagged_test.go", []byte(fxTagged))
+	defer fx.QuitAll()
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	<-diff
+	fx.isWatched <- true // blocks diffing until its answer is read
+	integration := &Profile{Name: "integration",
+		Tags: []string{"integration"}}
+
+	switched := make(chan struct{})
+	go func() {
+		fx.SetProfile(&Profile{Name: "other"})
+		fx.SetProfile(integration)
+		close(switched)
+	}()
+	select {
+	case <-switched:
+		<-fx.isWatched
+	case <-t.Timeout(time.Second):
+		<-fx.isWatched
+		t.Fatal("expected profile switches not to block")
+	}
+
+	select {
+	case update := <-diff:
+		tp, ok := reportedIDs(update)["tagged"]
+		t.FatalIfNot(t.True(ok))
+		t.True(tp.Profile == integration)
+	case <-t.Timeout(time.Second):
+		t.Fatal("expected diff-report of profile switch")
+	}
+}
+
 func TestSource(t *testing.T) {
 	t.Parallel()
 	Run(&source{}, t)
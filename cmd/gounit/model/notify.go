@@ -0,0 +1,52 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package model
+
+import "errors"
+
+// Backend selects how [Sources] detects changes of its watched
+// directory.
+type Backend uint8
+
+const (
+
+	// AutoBackend is the zero Backend which selects NotifyBackend if
+	// the platform provides file system notifications; PollBackend
+	// otherwise.
+	AutoBackend Backend = iota
+
+	// PollBackend traverses the watched directory every
+	// Sources.Interval to calculate its packages' stats.
+	PollBackend
+
+	// NotifyBackend calculates the watched directory's packages' stats
+	// only if the file system reported a change since the last
+	// calculation.  Changes are collected for at least
+	// Sources.Interval.
+	NotifyBackend
+)
+
+// ErrNotify is returned by [Sources.Watch] if NotifyBackend is selected
+// while file system notifications are not available.
+var ErrNotify = errors.New("model: file system notifications unavailable")
+
+// A notifier signals changes in a watched directory and its
+// descendants through its changed channel.  Directories created at
+// runtime are watched as well.  Signals are coalesced, i.e. a signal
+// which wasn't received yet stands for all following changes.
+type notifier struct {
+	changed chan struct{}
+
+	// close stops watching and releases the notifier's resources.
+	close func()
+}
+
+// signal informs about a change without blocking.
+func (n *notifier) signal() {
+	select {
+	case n.changed <- struct{}{}:
+	default:
+	}
+}
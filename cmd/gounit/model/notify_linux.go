@@ -0,0 +1,171 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask selects the events of a watched directory which may
+// change its packages' stats.
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY |
+	unix.IN_ATTRIB | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_DELETE_SELF | unix.IN_ONLYDIR
+
+// inotify watches a directory tree with one inotify watch per
+// directory.  Its maps are only accessed by the go routine reading the
+// inotify events once watching has started.
+type inotify struct {
+	fd     int
+	file   *os.File
+	root   string
+	ignore func(string) bool
+
+	// wds maps watch descriptors to their directories and dirs
+	// directories to their watch descriptors.
+	wds  map[int]string
+	dirs map[string]int
+}
+
+// newNotifier watches given directory and its not ignored descendants
+// for changes using inotify.  A wrapped ErrNotify is returned if
+// inotify is not available or given directory can't be watched.
+func newNotifier(dir string, ignore func(string) bool) (*notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotify, err)
+	}
+	in := &inotify{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		root:   dir,
+		ignore: ignore,
+		wds:    map[int]string{},
+		dirs:   map[string]int{},
+	}
+	if err := in.addTree(dir); err != nil {
+		in.file.Close()
+		return nil, fmt.Errorf("%w: %v", ErrNotify, err)
+	}
+	n := &notifier{changed: make(chan struct{}, 1)}
+	n.close = func() { in.file.Close() }
+	go in.read(n)
+	return n, nil
+}
+
+// addTree adds a watch for given directory and each of its not ignored
+// descendants which isn't watched yet.  Directories vanishing while
+// they are added are skipped.
+func (in *inotify) addTree(dir string) error {
+	stk := dirStack{dir}
+	for len(stk) > 0 {
+		d := stk.Pop()
+		if _, ok := in.dirs[d]; !ok {
+			wd, err := unix.InotifyAddWatch(in.fd, d, inotifyMask)
+			if err != nil {
+				if errors.Is(err, unix.ENOENT) ||
+					errors.Is(err, unix.ENOTDIR) {
+					continue
+				}
+				return fmt.Errorf("watch %s: %w", d, err)
+			}
+			in.wds[wd], in.dirs[d] = d, wd
+		}
+		if err := stk.PushDir(d, in.ignore); err != nil {
+			continue
+		}
+	}
+	return nil
+}
+
+// removeTree removes the watches of given directory and its
+// descendants, e.g. if it was moved away.
+func (in *inotify) removeTree(dir string) {
+	prefix := dir + string(os.PathSeparator)
+	for d, wd := range in.dirs {
+		if d != dir && !strings.HasPrefix(d, prefix) {
+			continue
+		}
+		unix.InotifyRmWatch(in.fd, uint32(wd))
+		delete(in.dirs, d)
+		delete(in.wds, wd)
+	}
+}
+
+// read reads inotify events until its file is closed and signals given
+// notifier about each batch of read events which may change the watched
+// directory's packages' stats.
+func (in *inotify) read(n *notifier) {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		l, err := in.file.Read(buf)
+		if err != nil {
+			return
+		}
+		changed := false
+		for off := 0; off+unix.SizeofInotifyEvent <= l; {
+			e := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			start := off + unix.SizeofInotifyEvent
+			off = start + int(e.Len)
+			name := strings.TrimRight(string(buf[start:off]), "\x00")
+			if in.handle(e, name) {
+				changed = true
+			}
+		}
+		if changed {
+			n.signal()
+		}
+	}
+}
+
+// handle updates the watched directories according to given event
+// having given name and returns true if the event may have changed the
+// watched directory's packages' stats.
+func (in *inotify) handle(e *unix.InotifyEvent, name string) bool {
+	if e.Mask&unix.IN_Q_OVERFLOW != 0 {
+		// events were lost, hence we may have missed new directories
+		in.addTree(in.root)
+		return true
+	}
+	dir, ok := in.wds[int(e.Wd)]
+	if !ok {
+		return false
+	}
+	if e.Mask&unix.IN_IGNORED != 0 { // watched directory was removed
+		delete(in.wds, int(e.Wd))
+		if in.dirs[dir] == int(e.Wd) {
+			delete(in.dirs, dir)
+		}
+		return false
+	}
+	if name == "" {
+		return true
+	}
+	path := filepath.Join(dir, name)
+	if in.ignore(path) {
+		return false
+	}
+	if e.Mask&unix.IN_ISDIR == 0 {
+		return true
+	}
+	switch {
+	case e.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+		// watch before the change is signaled to not miss files
+		// created in the new directory after its stats calculation
+		in.addTree(path)
+	case e.Mask&unix.IN_MOVED_FROM != 0:
+		in.removeTree(path)
+	}
+	return true
+}
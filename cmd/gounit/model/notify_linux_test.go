@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+)
+
+type inotifyBackend struct{ Suite }
+
+func (s *inotifyBackend) SetUp(t *T) { t.Parallel() }
+
+// isSignaled returns true iff given notifier signals a change within a
+// second.
+func isSignaled(t *T, n *notifier) bool {
+	select {
+	case <-n.changed:
+		return true
+	case <-t.Timeout(time.Second):
+		return false
+	}
+}
+
+func (s *inotifyBackend) Signals_changed_files(t *T) {
+	dir := t.FS().Tmp()
+	dir.MkFile("a.go", []byte("package a\n"))
+	n, err := newNotifier(dir.Path(), ignoreClosure(DefaultIgnore...))
+	t.FatalOn(err)
+	defer n.close()
+
+	dir.Touch("a.go")
+
+	t.True(isSignaled(t, n))
+}
+
+func (s *inotifyBackend) Watches_directories_created_at_runtime(t *T) {
+	dir := t.FS().Tmp()
+	n, err := newNotifier(dir.Path(), ignoreClosure(DefaultIgnore...))
+	t.FatalOn(err)
+	defer n.close()
+
+	nested, _ := dir.Mk("a", "b")
+	t.FatalIfNot(t.True(isSignaled(t, n)))
+	time.Sleep(10 * time.Millisecond) // let nested be watched
+	select {
+	case <-n.changed: // drain the signal of b's creation
+	default:
+	}
+	nested.MkFile("b.go", []byte("package b\n"))
+
+	t.True(isSignaled(t, n))
+}
+
+func (s *inotifyBackend) Ignores_changes_in_ignored_directories(t *T) {
+	dir := t.FS().Tmp()
+	testdata, _ := dir.Mk("testdata")
+	n, err := newNotifier(dir.Path(), ignoreClosure(DefaultIgnore...))
+	t.FatalOn(err)
+	defer n.close()
+
+	testdata.MkFile("golden.txt", []byte("golden\n"))
+
+	select {
+	case <-n.changed:
+		t.Error("unexpected change of ignored directory")
+	case <-t.Timeout(50 * time.Millisecond):
+	}
+}
+
+func (s *inotifyBackend) Reports_packages_of_directories_created_at_runtime(
+	t *T,
+) {
+	fx := NewFX(t).Set(FxMod | FxTestingPackage)
+	fx.Interval = 1 * time.Millisecond
+	fx.Backend = NotifyBackend
+	defer fx.QuitAll()
+
+	diff, _, err := fx.Watch()
+	t.FatalOn(err)
+	select {
+	case <-diff:
+	case <-t.Timeout(time.Second):
+		t.Fatal("expected initial diff-report")
+	}
+
+	pkgDir, _ := fx.FxDir.Mk("nested", "pkg")
+	pkgDir.MkPkgTest(fxTestFileName, []byte(fxTest))
+
+	select {
+	case add := <-diff:
+		n := 0
+		add.For(func(tp *TestingPackage) (stop bool) {
+			n++
+			t.Eq("nested/pkg", tp.ID())
+			return false
+		})
+		t.Eq(1, n)
+	case <-t.Timeout(time.Second):
+		t.Fatal("expected diff-report for package in new directory")
+	}
+}
+
+func TestInotifyBackend(t *testing.T) {
+	t.Parallel()
+	Run(&inotifyBackend{}, t)
+}
@@ -0,0 +1,13 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package model
+
+// newNotifier fails with ErrNotify since file system notifications are
+// only supported on linux.
+func newNotifier(string, func(string) bool) (*notifier, error) {
+	return nil, ErrNotify
+}
@@ -22,13 +22,16 @@ type PackagesDiff struct {
 
 // For returns all testing packages which were updated since the last
 // reported diff in descending order by their modification time.
+// Testing packages which weren't updated themselves but import directly
+// or indirectly a changed package of the watched directory are reported
+// as well, see [TestingPackage.Affected].
 func (d *PackagesDiff) For(cb func(*TestingPackage) (stop bool)) error {
-	pp := []*TestingPackage{}
+	pp, affected := []*TestingPackage{}, d.current.affected(d.last)
 	for _, ps := range d.current.pp {
-		if !d.last.isUpdatedBy(ps) {
+		if !d.last.isUpdatedBy(ps) && !affected[ps.ID()] {
 			continue
 		}
-		tt, err := ps.loadTestFiles()
+		tt, err := ps.loadTestFiles(d.current.profile)
 		if err != nil {
 			return err
 		}
@@ -38,7 +41,9 @@ func (d *PackagesDiff) For(cb func(*TestingPackage) (stop bool)) error {
 		}
 		pp = append(pp, &TestingPackage{
 			ModTime: ps.ModTime,
-			id:      id, abs: ps.abs, files: tt, Timeout: d.timeout})
+			id:      id, abs: ps.abs, files: tt, Timeout: d.timeout,
+			Profile:  d.current.profile,
+			affected: affected[ps.ID()]})
 	}
 	sort.Slice(pp, func(i, j int) bool {
 		return pp[i].ModTime.After(pp[j].ModTime)
@@ -64,6 +69,10 @@ func (d *PackagesDiff) String() string {
 	default:
 		ss = append(ss, "pkg-diff: updated:")
 		for _, d := range dd {
+			if d.Affected() {
+				ss = append(ss, fmt.Sprintf("  %s (affected)", d.ID()))
+				continue
+			}
 			ss = append(ss, fmt.Sprintf("  %s", d.ID()))
 		}
 	}
@@ -108,7 +117,8 @@ func (d *PackagesDiff) ForDel(cb func(*TestingPackage) (stop bool)) {
 
 // hasDelta returns true iff the two packages stats represent different
 // numbers of package stats or if a current package stat updates (or
-// misses) its corresponding last package stat.
+// misses) its corresponding last package stat or is affected by a
+// changed package.
 func (d *PackagesDiff) hasDelta() bool {
 	if d.last == nil && d.current == nil {
 		return false
@@ -131,5 +141,5 @@ func (d *PackagesDiff) hasDelta() bool {
 	// misses package stats of d.last it must have package stats which
 	// are not in d.last which trigger a true return value in the above
 	// case already.
-	return false
+	return len(d.current.affected(d.last)) > 0
 }
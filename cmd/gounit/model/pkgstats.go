@@ -5,11 +5,13 @@
 package model
 
 import (
+	"crypto/sha256"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -21,6 +23,18 @@ import (
 type packagesStat struct {
 	ModTime time.Time
 	pp      []*pkgStat
+
+	// module is the import path of the module whose packages are
+	// stated.
+	module string
+
+	// libs are the stats of the module's packages which are not
+	// testing packages.  They are needed to determine the testing
+	// packages which are affected by a changed package.
+	libs []*pkgStat
+
+	// profile is the profile the packages were stated with.
+	profile *Profile
 }
 
 // diff returns a new PackagesDiff iff there are differences between
@@ -41,9 +55,9 @@ func (pp *packagesStat) diff(other *packagesStat) *PackagesDiff {
 }
 
 // isUpdatedBy returns true iff given package stats not included in
-// receiving packages stats or if its modification time is after the
-// modification time of corresponding package stats of receiving
-// packages stats.
+// receiving packages stats or if the content of its go source files
+// differs from the content of the corresponding package stats' go
+// source files of receiving packages stats.
 func (pp *packagesStat) isUpdatedBy(tp *pkgStat) bool {
 	if pp == nil {
 		return true
@@ -52,13 +66,103 @@ func (pp *packagesStat) isUpdatedBy(tp *pkgStat) bool {
 		if _tp.ID() != tp.ID() {
 			continue
 		}
-		if !tp.ModTime.After(_tp.ModTime) {
+		if tp.sum == _tp.sum {
 			return false
 		}
 	}
 	return true
 }
 
+// get returns the (testing) package stats of receiving packages stats
+// with given absolute path; nil if there is none.
+func (pp *packagesStat) get(abs string) *pkgStat {
+	if pp == nil {
+		return nil
+	}
+	for _, tp := range pp.pp {
+		if tp.abs != abs {
+			continue
+		}
+		return tp
+	}
+	for _, tp := range pp.libs {
+		if tp.abs != abs {
+			continue
+		}
+		return tp
+	}
+	return nil
+}
+
+// all returns the stats of receiving packages stats' testing packages
+// and libs.
+func (pp *packagesStat) all() []*pkgStat {
+	return append(append([]*pkgStat{}, pp.pp...), pp.libs...)
+}
+
+// importPath returns the import path of given package stats of
+// receiving packages stats.
+func (pp *packagesStat) importPath(ps *pkgStat) string {
+	if ps.rel == "" {
+		return pp.module
+	}
+	return pp.module + "/" + filepath.ToSlash(ps.rel)
+}
+
+// affected returns the IDs of the testing packages of receiving
+// packages stats which are not updated compared to given last packages
+// stats but which import directly or indirectly a package whose go
+// sources, ignoring its test files, have changed, been added or
+// been removed since given last packages stats.
+func (pp *packagesStat) affected(last *packagesStat) map[string]bool {
+	if pp == nil || last == nil {
+		return nil
+	}
+	changed, stack := map[string]bool{}, []string{}
+	for _, ps := range pp.all() {
+		_ps := last.get(ps.abs)
+		if _ps != nil && _ps.srcSum == ps.srcSum {
+			continue
+		}
+		changed[pp.importPath(ps)] = true
+	}
+	for _, ps := range last.all() {
+		if pp.get(ps.abs) != nil {
+			continue
+		}
+		changed[last.importPath(ps)] = true
+	}
+	for ip := range changed {
+		stack = append(stack, ip)
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+
+	importers := map[string][]*pkgStat{}
+	for _, ps := range pp.all() {
+		for _, ip := range ps.imports {
+			importers[ip] = append(importers[ip], ps)
+		}
+	}
+	affected := map[string]bool{}
+	for len(stack) > 0 {
+		ip := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, ps := range importers[ip] {
+			if changed[pp.importPath(ps)] {
+				continue
+			}
+			changed[pp.importPath(ps)] = true
+			stack = append(stack, pp.importPath(ps))
+			if ps.testing && !last.isUpdatedBy(ps) {
+				affected[ps.ID()] = true
+			}
+		}
+	}
+	return affected
+}
+
 // has returns true iff receiving packages stats have package stats with
 // the same relative name as given package stats.
 func (pp *packagesStat) has(tp *pkgStat) bool {
@@ -101,29 +205,56 @@ func (s *dirStack) PushDir(dir string, ignore func(string) bool) error {
 	return nil
 }
 
-// calcPackagesStat traverses given directory -- excluding the ones for
-// which ignore is true -- and adds a given directory as a testing
-// package's package stats iff it contains at least one *_test.go file
-// which contains at least one test function.  The ModTime-property is
-// the modification time of the most recently modified package.
-func calcPackagesStat(
-	moduleDir, dir string, ignore func(string) bool,
-) *packagesStat {
+// statsConfig configures the calculation of a watched directory's
+// packages stats.
+type statsConfig struct {
+
+	// moduleDir is the directory of the go module with given import
+	// path module whose directory dir is watched.
+	moduleDir, module, dir string
+
+	// ignore returns true for directories which are not traversed.
+	ignore func(string) bool
+
+	// inputs are glob patterns of package inputs, see [packageInputs].
+	inputs []string
+
+	// profile determines which test files are built, see
+	// [Profile.matches].
+	profile *Profile
+}
+
+// calcPackagesStat traverses given configuration's directory --
+// excluding the ones for which its ignore is true -- and adds a
+// directory as a testing package's package stats iff it contains at
+// least one *_test.go file built by the configured profile which
+// contains at least one test function.  Other directories with go
+// source files are added as libs.  The ModTime-property is the
+// modification time of the most recently modified testing package.
+// Given last packages stats may be nil; otherwise the content hashes of
+// its go source files are reused for files whose modification time and
+// size didn't change.
+func calcPackagesStat(cfg *statsConfig, last *packagesStat) *packagesStat {
 
-	stk := dirStack{dir}
-	pp := packagesStat{}
+	stk := dirStack{cfg.dir}
+	pp := packagesStat{module: cfg.module, profile: cfg.profile}
 
 	for len(stk) > 0 {
 		d := stk.Pop()
-		if err := stk.PushDir(d, ignore); err != nil {
+		if err := stk.PushDir(d, cfg.ignore); err != nil {
 			continue
 		}
-		tp, ok := newTestingPackageStat(d)
+		tp, ok := newPackageStat(d, last.get(d), cfg)
 		if !ok {
 			continue
 		}
 		tp.rel = strings.TrimLeft(
-			strings.TrimPrefix(tp.abs, moduleDir), string(os.PathSeparator))
+			strings.TrimPrefix(tp.abs, cfg.moduleDir),
+			string(os.PathSeparator))
+		if !tp.testing {
+			pp.libs = append(pp.libs, tp)
+			continue
+		}
 		pp.pp = append(pp.pp, tp)
 		if tp.ModTime.After(pp.ModTime) {
 			pp.ModTime = tp.ModTime
@@ -138,6 +269,36 @@ func calcPackagesStat(
 type pkgStat struct {
 	ModTime  time.Time
 	abs, rel string
+
+	// testing is true iff the package has at least one test file with
+	// at least one test.
+	testing bool
+
+	// sum is the content hash of the package's go source files which
+	// is calculated from their names and files' content hashes.
+	sum [sha256.Size]byte
+
+	// srcSum is calculated like sum ignoring test files, i.e. it
+	// changes only if a change may affect importing packages.
+	srcSum [sha256.Size]byte
+
+	// imports are the import paths of the package's go source files
+	// including its test files.
+	imports []string
+
+	// files maps the package relative paths of the package's go source
+	// files and inputs to their content hashes.
+	files map[string]fileSum
+}
+
+// fileSum is the content hash of a go source file having given
+// modification time and size.
+type fileSum struct {
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
+	imports []string
+	embeds  []string
 }
 
 // newTestingPackageStat evaluates if given directory has at least one
@@ -146,8 +307,26 @@ type pkgStat struct {
 // testing package's modification time is determined as the most recent
 // modification time of all *.go source files in this package.  I.e. if
 // a package contains none go source files their update have no effect
-// on the testing package's modification time.
-func newTestingPackageStat(dir string) (*pkgStat, bool) {
+// on the testing package's modification time.  Given last stats of the
+// same directory may be nil, see [pkgStat.calcSum].
+func newTestingPackageStat(dir string, last *pkgStat) (*pkgStat, bool) {
+	stat, ok := newPackageStat(dir, last, &statsConfig{})
+	if !ok || !stat.testing {
+		return nil, false
+	}
+	return stat, true
+}
+
+// newPackageStat evaluates if given directory has at least one go
+// source file in which case a new *pkgStat instance and true is
+// returned; otherwise nil and false.  The returned stat's testing
+// property is set iff given directory is a testing package, see
+// [newTestingPackageStat] whereas only test files built by given
+// configuration's profile are considered.  The configuration's inputs
+// are glob patterns of the package's inputs, see [pkgStat.calcSum].
+func newPackageStat(
+	dir string, last *pkgStat, cfg *statsConfig,
+) (*pkgStat, bool) {
 
 	stt, err := os.Stat(dir)
 	if err != nil || !stt.IsDir() {
@@ -159,6 +338,7 @@ func newTestingPackageStat(dir string) (*pkgStat, bool) {
 	}
 
 	stat, testing := &pkgStat{abs: dir, ModTime: stt.ModTime()}, false
+	ff := []os.FileInfo{}
 	// init := true
 	for _, e := range entries {
 		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
@@ -168,6 +348,7 @@ func newTestingPackageStat(dir string) (*pkgStat, bool) {
 		if err != nil {
 			continue
 		}
+		ff = append(ff, s)
 		if stat.ModTime.Before(s.ModTime()) {
 			stat.ModTime = s.ModTime()
 		}
@@ -182,23 +363,156 @@ func newTestingPackageStat(dir string) (*pkgStat, bool) {
 		if !strings.HasSuffix(e.Name(), "_test.go") {
 			continue
 		}
-		if !isTesting(filepath.Join(dir, e.Name())) {
+		if !isTesting(filepath.Join(dir, e.Name()), cfg.profile) {
 			continue
 		}
 		testing = true
 	}
-	if !testing {
+	if len(ff) == 0 {
 		return nil, false
 	}
 
+	stat.testing = testing
+	stat.calcSum(ff, last, cfg)
 	return stat, true
 }
 
-// isTesting returns true if given file contains at least one function
-// declaration (without receiver) whose name is prefixed with "Test";
-// otherwise false.
-func isTesting(file string) bool {
-	fl, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+// calcSum calculates the content hashes and imports of given go source
+// files of receiving package stats.  The content hashes of the
+// package's inputs, i.e. the files matched by the go sources' go:embed
+// directives, the files of the package's testdata directory and the
+// files matching given configuration's input patterns, are added to
+// the package's content hash.  Embedded files of non-test go sources
+// are also added to the content hash which is relevant to importers.
+// The configured profile is part of the package's content hash, i.e. a
+// profile switch is reported as a change of all testing packages.  A
+// file's content is only read if given last package stats is nil or it
+// has no hash of given file with the same modification time and size.
+func (ps *pkgStat) calcSum(
+	ff []os.FileInfo, last *pkgStat, cfg *statsConfig,
+) {
+	h, src := sha256.New(), sha256.New()
+	h.Write([]byte(cfg.profile.key()))
+	ps.files = make(map[string]fileSum, len(ff))
+	imports, embeds, srcEmbeds := map[string]bool{}, []string{}, []string{}
+	for _, fi := range ff {
+		fs, ok := ps.hash(fi.Name(), fi, last, true)
+		if !ok {
+			continue
+		}
+		h.Write([]byte(fi.Name()))
+		h.Write(fs.sum[:])
+		embeds = append(embeds, fs.embeds...)
+		if !strings.HasSuffix(fi.Name(), "_test.go") {
+			src.Write([]byte(fi.Name()))
+			src.Write(fs.sum[:])
+			srcEmbeds = append(srcEmbeds, fs.embeds...)
+		}
+		for _, ip := range fs.imports {
+			if imports[ip] {
+				continue
+			}
+			imports[ip] = true
+			ps.imports = append(ps.imports, ip)
+		}
+	}
+	embedded := map[string]bool{}
+	for _, i := range packageInputs(ps.abs, srcEmbeds, false) {
+		embedded[i.rel] = true
+	}
+	for _, i := range packageInputs(
+		ps.abs, append(embeds, cfg.inputs...), true) {
+
+		fs, ok := ps.hash(i.rel, i.fi, last, false)
+		if !ok {
+			continue
+		}
+		if ps.ModTime.Before(i.fi.ModTime()) {
+			ps.ModTime = i.fi.ModTime()
+		}
+		h.Write([]byte(i.rel))
+		h.Write(fs.sum[:])
+		if embedded[i.rel] {
+			src.Write([]byte(i.rel))
+			src.Write(fs.sum[:])
+		}
+	}
+	copy(ps.sum[:], h.Sum(nil))
+	copy(ps.srcSum[:], src.Sum(nil))
+}
+
+// hash returns the content hash of the file with given package relative
+// path and file info and adds it to receiving package stats' files.
+// The hash of given last package stats is reused if it was calculated
+// for the same modification time and size.  The imports and go:embed
+// patterns of a file are parsed iff isGo is true.  False is returned if
+// the file can't be read.
+func (ps *pkgStat) hash(
+	rel string, fi os.FileInfo, last *pkgStat, isGo bool,
+) (fileSum, bool) {
+	fs, ok := last.fileSum(rel, fi)
+	if !ok {
+		name := filepath.Join(ps.abs, rel)
+		bb, err := os.ReadFile(name)
+		if err != nil {
+			return fileSum{}, false
+		}
+		fs = fileSum{
+			modTime: fi.ModTime(),
+			size:    fi.Size(),
+			sum:     sha256.Sum256(bb),
+		}
+		if isGo {
+			fs.imports = parseImports(name, bb)
+			fs.embeds = parseEmbeds(bb)
+		}
+	}
+	ps.files[rel] = fs
+	return fs, true
+}
+
+// parseImports returns the import paths of given go source file with
+// given content; nil if it can't be parsed.
+func parseImports(name string, content []byte) (ii []string) {
+	fl, err := parser.ParseFile(
+		token.NewFileSet(), name, content, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+	for _, i := range fl.Imports {
+		ip, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+		ii = append(ii, ip)
+	}
+	return ii
+}
+
+// fileSum returns the content hash of the file with given package
+// relative path and file info and true if receiving package stats has
+// it calculated for the file's modification time and size; otherwise
+// false.
+func (ps *pkgStat) fileSum(rel string, fi os.FileInfo) (fileSum, bool) {
+	if ps == nil {
+		return fileSum{}, false
+	}
+	fs, ok := ps.files[rel]
+	if !ok || fs.size != fi.Size() || !fs.modTime.Equal(fi.ModTime()) {
+		return fileSum{}, false
+	}
+	return fs, true
+}
+
+// isTesting returns true if given file is built by given profile and
+// contains at least one function declaration (without receiver) whose
+// name is prefixed with "Test"; otherwise false.
+func isTesting(file string, prf *Profile) bool {
+	bb, err := os.ReadFile(file)
+	if err != nil || !prf.matches(filepath.Base(file), bb) {
+		return false
+	}
+	fl, err := parser.ParseFile(token.NewFileSet(), file, bb, 0)
 	if err != nil {
 		return false
 	}
@@ -233,9 +547,12 @@ func (ps pkgStat) Abs() string { return filepath.Dir(ps.abs) }
 // I.e. ID() is a module-global unique identifier of a testing package.
 func (ps pkgStat) ID() string { return ps.rel }
 
-// loadTestFiles reads the test-files of a testing package to order
-// parsed suits by modification time of their associated test files.
-func (ps *pkgStat) loadTestFiles() (tt []*testFile, err error) {
+// loadTestFiles reads the test-files of a testing package which are
+// built by given profile to order parsed suits by modification time of
+// their associated test files.
+func (ps *pkgStat) loadTestFiles(prf *Profile) (
+	tt []*testFile, err error,
+) {
 	ee, err := os.ReadDir(ps.abs)
 	if err != nil {
 		return nil, err
@@ -253,6 +570,9 @@ func (ps *pkgStat) loadTestFiles() (tt []*testFile, err error) {
 		if err != nil {
 			return nil, err
 		}
+		if !prf.matches(e.Name(), bb) {
+			continue
+		}
 		tt = append(
 			tt, &testFile{
 				modTime: stt.ModTime(),
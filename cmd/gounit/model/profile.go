@@ -0,0 +1,256 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/build"
+	"go/build/constraint"
+	"os"
+	"strings"
+)
+
+// A Profile is a named set of build and run settings of test runs.  A
+// profile's build settings determine which test files are considered
+// for the discovery of testing packages and the parsing of their tests
+// and suites, i.e. a package's tests are reported as the profile will
+// run them, e.g.:
+//
+//	integration := &Profile{Name: "integration",
+//	    Tags: []string{"integration"}, Count: 1}
+//	x86 := &Profile{Name: "386", GOARCH: "386", Short: true}
+//
+// A nil profile is the DefaultProfile.  A profile's json keys are its
+// lower cased field names.
+type Profile struct {
+
+	// Name identifies a profile, e.g. in the user interface.
+	Name string `json:"name"`
+
+	// Tags are the build tags of a test run, i.e. its -tags flag.
+	Tags []string `json:"tags"`
+
+	// Env are additional environment variables of a test run in the
+	// form "KEY=value".
+	Env []string `json:"env"`
+
+	// Args are additional go test flags of a test run, e.g.
+	// "-failfast".
+	Args []string `json:"args"`
+
+	// Count sets a test run's -count flag iff positive.
+	Count int `json:"count"`
+
+	// Short sets a test run's -short flag.
+	Short bool `json:"short"`
+
+	// CPU sets a test run's -cpu flag iff not empty, e.g. "1,2,4".
+	CPU string `json:"cpu"`
+
+	// GOOS and GOARCH default to the go tool's target operating system
+	// and architecture, e.g. GOARCH "386" runs the tests of a package
+	// compiled for 32 bit x86 on an amd64 machine.
+	GOOS   string `json:"goos"`
+	GOARCH string `json:"goarch"`
+}
+
+// DefaultProfile runs tests with the go tool's default settings.
+var DefaultProfile = &Profile{Name: "default"}
+
+func (p *Profile) orDefault() *Profile {
+	if p == nil {
+		return DefaultProfile
+	}
+	return p
+}
+
+func (p *Profile) goos() string {
+	if p.orDefault().GOOS == "" {
+		return build.Default.GOOS
+	}
+	return p.GOOS
+}
+
+func (p *Profile) goarch() string {
+	if p.orDefault().GOARCH == "" {
+		return build.Default.GOARCH
+	}
+	return p.GOARCH
+}
+
+// args returns the go test flags of given profile p.
+func (p *Profile) args() (aa []string) {
+	p = p.orDefault()
+	if len(p.Tags) > 0 {
+		aa = append(aa, "-tags="+strings.Join(p.Tags, ","))
+	}
+	if p.Count > 0 {
+		aa = append(aa, fmt.Sprintf("-count=%d", p.Count))
+	}
+	if p.Short {
+		aa = append(aa, "-short")
+	}
+	if p.CPU != "" {
+		aa = append(aa, "-cpu="+p.CPU)
+	}
+	return append(aa, p.Args...)
+}
+
+// env returns the environment of a test run with given profile p; nil
+// if p doesn't change the environment.
+func (p *Profile) env() []string {
+	p = p.orDefault()
+	if len(p.Env) == 0 && p.GOOS == "" && p.GOARCH == "" {
+		return nil
+	}
+	env := append(os.Environ(), p.Env...)
+	if p.GOOS != "" {
+		env = append(env, "GOOS="+p.GOOS)
+	}
+	if p.GOARCH != "" {
+		env = append(env, "GOARCH="+p.GOARCH)
+	}
+	return env
+}
+
+// key identifies given profile p's settings.
+func (p *Profile) key() string {
+	p = p.orDefault()
+	return fmt.Sprintf("%s|%v|%v|%v|%d|%v|%s|%s|%s", p.Name, p.Tags,
+		p.Env, p.Args, p.Count, p.Short, p.CPU, p.goos(), p.goarch())
+}
+
+// cgo returns true iff cgo is enabled for given profile p.
+func (p *Profile) cgo() bool {
+	for _, e := range p.orDefault().Env {
+		if strings.HasPrefix(e, "CGO_ENABLED=") {
+			return e == "CGO_ENABLED=1"
+		}
+	}
+	if p.goos() != build.Default.GOOS ||
+		p.goarch() != build.Default.GOARCH {
+
+		return false
+	}
+	return build.Default.CgoEnabled
+}
+
+// hasTag returns true iff given build tag is satisfied by given profile
+// p.
+func (p *Profile) hasTag(tag string) bool {
+	switch {
+	case p.matchesOS(tag) || tag == p.goarch():
+		return true
+	case tag == "unix":
+		return unixOS[p.goos()]
+	case tag == "cgo":
+		return p.cgo()
+	case tag == build.Default.Compiler:
+		return true
+	}
+	for _, t := range build.Default.ReleaseTags {
+		if t == tag {
+			return true
+		}
+	}
+	for _, t := range p.orDefault().Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true iff the go source file with given name and
+// content is built by given profile p, i.e. if p satisfies the file's
+// GOOS/GOARCH name suffixes and its build constraints.
+func (p *Profile) matches(name string, content []byte) bool {
+	return p.matchesName(name) && p.matchesConstraints(content)
+}
+
+// matchesName evaluates the GOOS and GOARCH suffixes of given file
+// name, e.g. "net_linux_amd64_test.go".
+func (p *Profile) matchesName(name string) bool {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".go"), "_test")
+	ss := strings.Split(name, "_")
+	if n := len(ss); n >= 3 && knownOS[ss[n-2]] && knownArch[ss[n-1]] {
+		return p.matchesOS(ss[n-2]) && ss[n-1] == p.goarch()
+	}
+	if n := len(ss); n >= 2 {
+		if knownOS[ss[n-1]] {
+			return p.matchesOS(ss[n-1])
+		}
+		if knownArch[ss[n-1]] {
+			return ss[n-1] == p.goarch()
+		}
+	}
+	return true
+}
+
+// matchesOS returns true iff given operating system is given profile
+// p's target operating system or implied by it, e.g. "linux" is implied
+// by "android".
+func (p *Profile) matchesOS(goos string) bool {
+	return goos == p.goos() ||
+		goos == "linux" && p.goos() == "android" ||
+		goos == "solaris" && p.goos() == "illumos" ||
+		goos == "darwin" && p.goos() == "ios"
+}
+
+// matchesConstraints evaluates the build constraints of given go
+// source's header.  A //go:build line takes precedence over // +build
+// lines.
+func (p *Profile) matchesConstraints(content []byte) bool {
+	plusBuild, scanner := []constraint.Expr{}, bufio.NewScanner(
+		bytes.NewReader(content))
+	for scanner.Scan() {
+		l := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(l, "package ") {
+			break // constraints must precede the package clause
+		}
+		if !constraint.IsGoBuild(l) && !constraint.IsPlusBuild(l) {
+			continue
+		}
+		x, err := constraint.Parse(l)
+		if err != nil {
+			continue
+		}
+		if constraint.IsGoBuild(l) {
+			return x.Eval(p.hasTag)
+		}
+		plusBuild = append(plusBuild, x)
+	}
+	for _, x := range plusBuild {
+		if !x.Eval(p.hasTag) {
+			return false
+		}
+	}
+	return true
+}
+
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+	"linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+	"js": true, "linux": true, "nacl": true, "netbsd": true,
+	"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true,
+	"armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+	"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+	"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true,
+	"s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
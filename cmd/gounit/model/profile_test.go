@@ -0,0 +1,73 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package model
+
+import (
+	"go/build"
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type profile struct{ Suite }
+
+func (s *profile) SetUp(t *T) { t.Parallel() }
+
+func (s *profile) Has_no_flags_and_environment_by_default(t *T) {
+	var prf *Profile
+	t.Eq(0, len(prf.args()))
+	t.True(prf.env() == nil)
+	t.Eq(DefaultProfile.key(), prf.key())
+}
+
+func (s *profile) Provides_its_go_test_flags(t *T) {
+	prf := &Profile{Tags: []string{"a", "b"}, Count: 1, Short: true,
+		CPU: "1,2", Args: []string{"-failfast"}}
+	t.Eq([]string{"-tags=a,b", "-count=1", "-short", "-cpu=1,2",
+		"-failfast"}, prf.args())
+}
+
+func (s *profile) Sets_target_architecture_in_environment(t *T) {
+	env := (&Profile{GOARCH: "386"}).env()
+	t.Eq("GOARCH=386", env[len(env)-1])
+}
+
+func (s *profile) Evaluates_build_constraints(t *T) {
+	src := func(constraints string) []byte {
+		return []byte(constraints + "\npackage p\n")
+	}
+	integration := &Profile{Tags: []string{"integration"}}
+
+	t.True(integration.matchesConstraints(
+		src("//go:build integration")))
+	t.Not.True(DefaultProfile.matchesConstraints(
+		src("//go:build integration")))
+	t.True(DefaultProfile.matchesConstraints(
+		src("//go:build !integration")))
+	t.True(DefaultProfile.matchesConstraints(src(
+		"//go:build " + build.Default.GOOS + " && " +
+			build.Default.GOARCH)))
+	t.Not.True(integration.matchesConstraints(
+		src("// +build integration\n// +build ignore\n")))
+	t.True(DefaultProfile.matchesConstraints(
+		[]byte("package p\n\n//go:build ignore\n")))
+}
+
+func (s *profile) Evaluates_file_name_suffixes(t *T) {
+	x86 := &Profile{GOOS: "linux", GOARCH: "386"}
+
+	t.True(x86.matchesName("net_linux_test.go"))
+	t.True(x86.matchesName("net_386_test.go"))
+	t.True(x86.matchesName("net_linux_386.go"))
+	t.True(x86.matchesName("linux.go"))
+	t.Not.True(x86.matchesName("net_amd64_test.go"))
+	t.Not.True(x86.matchesName("net_windows_386_test.go"))
+	t.True((&Profile{GOOS: "android"}).matchesName("net_linux.go"))
+}
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+	Run(&profile{}, t)
+}
@@ -5,18 +5,19 @@
 package model
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"go/parser"
 	"go/token"
+	"io"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
-	"strings"
 	"time"
-	"unicode"
 
+	"github.com/slukits/gounit/pkg/testjson"
 	"github.com/slukits/ints"
 	"golang.org/x/exp/slices"
 )
@@ -37,6 +38,15 @@ type TestingPackage struct {
 	// srcStats caches once calculated source stats since a testing
 	// package is re-reported in case of an update.
 	srcStats *SrcStats
+
+	// affected is true iff a testing package is reported because of a
+	// changed imported package.
+	affected bool
+
+	// Profile determines a testing package's reported tests and the
+	// build and run settings of its test runs; nil is the
+	// DefaultProfile.
+	Profile *Profile
 }
 
 // Name returns the testing package's name.
@@ -55,6 +65,12 @@ func (tp TestingPackage) Rel() string { return filepath.Dir(tp.id) }
 // package.
 func (tp TestingPackage) ID() string { return tp.id }
 
+// Affected returns true iff given testing package is reported by a
+// [PackagesDiff] not because its go sources changed but because a
+// package of the watched directory changed which it imports directly or
+// indirectly.
+func (tp TestingPackage) Affected() bool { return tp.affected }
+
 // LenTests returns the number of go tests of a testing package.
 func (tp *TestingPackage) LenTests() int {
 	if err := tp.ensureParsing(); err != nil {
@@ -197,6 +213,9 @@ func (tp *TestingPackage) Suite(name string) *TestSuite {
 
 const StdErr = "shell exit error: "
 
+// ErrCanceled is returned by a test run whose context was canceled.
+var ErrCanceled = errors.New("test run canceled")
+
 // RunMask controls set flags for a test run.
 type RunMask uint8
 
@@ -207,9 +226,33 @@ const (
 	RunRace
 )
 
+// Progress reports the start or the end of a test or sub-test during a
+// test run of a testing package (see [TestingPackage.RunWithProgress]).
+type Progress struct {
+
+	// Test is the slash separated full name of the started or ended
+	// test.
+	Test string
+
+	// Action is one of testjson's actions Run, Pass, Fail or Skip.
+	Action string
+
+	// Running is the number of started tests which haven't ended yet.
+	Running int
+
+	// Passed and Failed count the so far passed and failed tests.
+	Passed, Failed int
+
+	// Elapsed is the duration since the test run has started.
+	Elapsed time.Duration
+}
+
 // Run executes go test for the testing package and returns its result.
 // Returned error if any is the error of command execution, i.e. a
-// timeout.  While Result.Err reflects errors from the error console.
+// timeout or the cancellation of given context (see [ErrCanceled]).
+// While Result.Err reflects errors from the error console.  A
+// succeeding test run without test events, e.g. because no test
+// matched a -run flag, has empty results.
 // Note the output of the go testing tool is sadly not enough to report
 // tests in the order they were written if tests run concurrently.
 // Hence to achieve the goal that the test reporting outlines the
@@ -217,10 +260,27 @@ const (
 // are reported in the order they were written, it is necessary to parse
 // the test files separately and then match the findings to the result
 // of the test run.
-func (tp *TestingPackage) Run(rm RunMask) (*Results, error) {
+func (tp *TestingPackage) Run(
+	ctx context.Context, rm RunMask,
+) (*Results, error) {
+	return tp.RunWithProgress(ctx, rm, nil)
+}
+
+// RunWithProgress executes go test for the testing package like
+// [TestingPackage.Run] whereas the test events are parsed as they are
+// reported by go test.  Given callback is called back for each started
+// or ended test from the go routine calling RunWithProgress before it
+// returns; e.g. the test which is started last and hasn't ended yet is
+// the most likely one which is hanging.
+func (tp *TestingPackage) RunWithProgress(
+	ctx context.Context, rm RunMask, progress func(*Progress),
+) (*Results, error) {
 	tp.parsed = false
-	ctx, cancel := context.WithTimeout(
-		context.Background(), tp.Timeout)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCanceled, tp.id)
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(parent, tp.Timeout)
 	defer cancel()
 	aa := []string{"test", "-json"}
 	if rm&RunVet == 0 {
@@ -229,28 +289,47 @@ func (tp *TestingPackage) Run(rm RunMask) (*Results, error) {
 	if rm&RunRace != 0 {
 		aa = append(aa, "-race")
 	}
+	aa = append(aa, tp.Profile.args()...)
 	aa = append(aa, fmt.Sprintf("-timeout=%s", tp.Timeout))
 	cmd := exec.CommandContext(ctx, "go", aa...)
 	cmd.Dir = tp.abs
+	cmd.Env = tp.Profile.env()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stderr = stderr
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", cmd.String(), err)
+	}
 	start := time.Now()
-	stdout, err := cmd.CombinedOutput()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s: %v", cmd.String(), err)
+	}
+	rr, jsonErr := decodeWithProgress(
+		io.TeeReader(pipe, stdout), start, progress)
+	err = cmd.Wait()
+	if parent.Err() != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCanceled, tp.id)
+	}
+	output := append(stdout.Bytes(), stderr.Bytes()...)
 	if err != nil {
 		if _, ok := err.(*exec.ExitError); !ok {
-			return nil, fmt.Errorf("%s: %s", cmd.String(), string(stdout))
+			return nil, fmt.Errorf("%s: %s", cmd.String(), string(output))
 		}
 	}
 	duration := time.Since(start)
-	rr, jsonErr := unmarshal(stdout)
+	if jsonErr == errNoTests && err == nil {
+		jsonErr = nil
+	}
 	if jsonErr != nil {
 		if err != nil {
 			return &Results{Duration: time.Since(start),
 				err: fmt.Sprintf("%s%v:\n%s",
-					StdErr, err, string(stdout))}, nil
+					StdErr, err, string(output))}, nil
 		}
 		return &Results{Duration: time.Since(start),
-			err: fmt.Sprintf("json-unmarshal stdout: %v", err)}, nil
+			err: fmt.Sprintf("json-unmarshal stdout: %v", jsonErr)}, nil
 	}
-	if err, ok := rr.hasPanic(); ok {
+	if err, ok := rr.HasPanic(); ok {
 		return &Results{
 			rr: rr, Duration: duration,
 			err: err,
@@ -259,6 +338,55 @@ func (tp *TestingPackage) Run(rm RunMask) (*Results, error) {
 	return &Results{rr: rr, Duration: duration}, nil
 }
 
+// errNoTests is returned by decodeWithProgress along with the empty
+// results of a test run without test events.
+var errNoTests = errors.New("no test events")
+
+// decodeWithProgress decodes go test events from given reader and
+// reports the start and end of tests to given progress callback.  Is
+// the reader's content not made of test events the reader is drained
+// and an error is returned.
+func decodeWithProgress(
+	r io.Reader, start time.Time, progress func(*Progress),
+) (*testjson.Results, error) {
+	d, p, tests := testjson.NewDecoder(r), &Progress{}, false
+	for {
+		e, err := d.Next()
+		if err == io.EOF {
+			if !tests {
+				return d.Results(), errNoTests
+			}
+			return d.Results(), nil
+		}
+		if err != nil {
+			io.Copy(io.Discard, r)
+			return nil, err
+		}
+		if e.Test == "" {
+			continue
+		}
+		tests = true
+		switch e.Action {
+		case testjson.Run:
+			p.Running++
+		case testjson.Pass, testjson.Skip:
+			p.Running--
+			p.Passed++
+		case testjson.Fail:
+			p.Running--
+			p.Failed++
+		default:
+			continue
+		}
+		if progress == nil {
+			continue
+		}
+		p.Test, p.Action, p.Elapsed = e.Test, e.Action, time.Since(start)
+		_p := *p
+		progress(&_p)
+	}
+}
+
 func (tp *TestingPackage) ensureParsing() error {
 	if tp.parsed {
 		return tp.parseErr
@@ -298,64 +426,14 @@ type Test struct {
 // Name returns a tests name.
 func (t *Test) Name() string { return t.name }
 
-var (
-	camelRe   = regexp.MustCompile(`\p{Lu}+[0-9.,!\- ]*`)
-	endsInNum = regexp.MustCompile(`\p{Lu}+[0-9.,!\- ]+`)
-	brokenEnd = regexp.MustCompile(`\p{Lu} \p{Ll}$`)
-)
-
 func (t *Test) String() string {
 	return HumanReadable(t.name)
 }
 
+// HumanReadable turns given test name into a human readable sentence,
+// see [testjson.HumanReadable].
 func HumanReadable(name string) string {
-	if strings.Contains(name, "_") {
-		name = strings.ReplaceAll(name, "_", " ")
-		for i, c := range name {
-			name = string(unicode.ToLower(c)) + name[i+1:]
-			break
-		}
-	}
-	return apostrophe(camelCaseToHuman(name))
-}
-
-func apostrophe(name string) string {
-	name = strings.ReplaceAll(name, " s ", "'s ")
-	name = strings.ReplaceAll(name, "dont", "don't")
-	name = strings.ReplaceAll(name, "doesnt", "doesn't")
-	name = strings.ReplaceAll(name, "havent", "haven't")
-	name = strings.ReplaceAll(name, "hasnt", "hasn't")
-	name = strings.ReplaceAll(name, "isnt", "isn't")
-	return name
-}
-
-func camelCaseToHuman(str string) string {
-	str = strings.TrimSpace(camelRe.ReplaceAllStringFunc(
-		str, func(s string) string {
-			if len(s) == 1 {
-				return " " + strings.ToLower(s)
-			}
-			if endsInNum.MatchString(s) {
-				return " " + s
-			}
-			return " " + s[:len(s)-1] + " " + strings.ToLower(string(s[len(s)-1]))
-		}))
-	str = strings.ReplaceAll(str, "  ", " ")
-	str = brokenEnd.ReplaceAllStringFunc(str, func(s string) string {
-		prefix := rune(0)
-		for i, r := range s {
-			if i == 0 {
-				prefix = r
-				continue
-			}
-			if r == ' ' {
-				continue
-			}
-			return string(prefix) + strings.ToUpper(string(r))
-		}
-		return s
-	})
-	return strings.TrimPrefix(str, "test ")
+	return testjson.HumanReadable(name)
 }
 
 // Pos returns a tests absolute filename with line and column number.
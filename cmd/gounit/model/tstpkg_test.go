@@ -5,6 +5,7 @@
 package model
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -136,7 +137,7 @@ func (s *Package) Reports_suite_runner(t *T) {
 func (s *Package) Reports_shell_exit_error_of_tests_run(t *T) {
 	pkg := s.fx.TestingPackage(t)
 
-	rslt, err := pkg.Run(0)
+	rslt, err := pkg.Run(context.Background(), 0)
 	t.FatalOn(err)
 
 	t.True(rslt.HasErr())
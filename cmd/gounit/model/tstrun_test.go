@@ -5,10 +5,13 @@
 package model
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
 	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/testjson"
 )
 
 type PkgTestRun struct {
@@ -34,7 +37,7 @@ func (s *PkgTestRun) Init(t *S) {
 	case <-_t.Timeout(30 * time.Millisecond):
 		t.Fatal("initial diff timed out")
 	}
-	rslt, err := pkg.Run(0)
+	rslt, err := pkg.Run(context.Background(), 0)
 	t.FatalOn(err)
 	_t.FatalIfNot(_t.True(rslt.Err() == ""))
 
@@ -57,7 +60,7 @@ func (s *PkgTestRun) Reports_a_result_for_each_suite(t *T) {
 func (s *PkgTestRun) Reports_results_for_suite_tests(t *T) {
 	s.pkg.ForSuite(func(st *TestSuite) {
 		st.ForTest(func(tst *Test) {
-			t.True(s.rslt.OfSuite(st).OfTest(tst) != nil)
+			t.True(s.rslt.OfSuite(st).Of(tst.Name()) != nil)
 		})
 	})
 }
@@ -80,10 +83,10 @@ func (s *PkgTestRun) Reports_failing_and_passing_of_suite_tests(
 		sr := s.rslt.OfSuite(ts)
 		ts.ForTest(func(tst *Test) {
 			if ts.Name() == fxSuiteB && tst.Name() == fxStBTest1 {
-				t.Not.True(sr.OfTest(tst).Passed)
+				t.Not.True(sr.Of(tst.Name()).Passed)
 				return
 			}
-			t.True(sr.OfTest(tst).Passed)
+			t.True(sr.Of(tst.Name()).Passed)
 		})
 	})
 }
@@ -130,7 +133,7 @@ func (s *PkgTestRun) Reports_suite_test_logs(t *T) {
 			if tst.Name() != fxStATest1 {
 				return
 			}
-			t.Eq(2, len(sr.OfTest(tst).Output))
+			t.Eq(2, len(sr.Of(tst.Name()).Output))
 		})
 	})
 }
@@ -151,3 +154,101 @@ func TestPkgTestRun(t *testing.T) {
 	t.Parallel()
 	Run(&PkgTestRun{}, t)
 }
+
+type PkgTestProgress struct{ Suite }
+
+func (s *PkgTestProgress) SetUp(t *T) { t.Parallel() }
+
+const fxProgress = `
+import "testing"
+
+func TestA(t *testing.T) { t.Run("sub", func(t *testing.T) {}) }
+
+func TestB(t *testing.T) { t.Error("failed") }
+`
+
+func (s *PkgTestProgress) Reports_started_and_ended_tests(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/fx")
+	dir, _ := mod.Mk("fx")
+	dir.MkPkgTest("fx", []byte(fxProgress))
+	tp := &TestingPackage{abs: dir.Path(), Timeout: DefaultTimeout}
+	pp := []Progress{}
+
+	rr, err := tp.RunWithProgress(context.Background(), 0, func(p *Progress) {
+		pp = append(pp, *p)
+	})
+
+	t.FatalOn(err)
+	t.Not.True(rr.Passed())
+	t.FatalIfNot(t.Eq(6, len(pp)))
+	t.Eq(testjson.Run, pp[0].Action)
+	t.Eq(1, pp[0].Running)
+	last := pp[len(pp)-1]
+	t.Eq(0, last.Running)
+	t.Eq(2, last.Passed)
+	t.Eq(1, last.Failed)
+	for _, p := range pp {
+		if p.Test == "TestB" && p.Action != testjson.Run {
+			t.Eq(testjson.Fail, p.Action)
+		}
+	}
+}
+
+func (s *PkgTestProgress) Reports_build_failures_as_result_error(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/fx")
+	dir, _ := mod.Mk("fx")
+	dir.MkPkgTest("fx", []byte("func F() int { return \"42\" }\n"))
+	tp := &TestingPackage{abs: dir.Path(), Timeout: DefaultTimeout}
+	called := false
+
+	rr, err := tp.RunWithProgress(context.Background(), 0, func(*Progress) { called = true })
+
+	t.FatalOn(err)
+	t.True(strings.HasPrefix(rr.Err(), StdErr))
+	t.Contains(rr.Err(), "fx_test.go:3")
+	t.Not.True(called)
+}
+
+func (s *PkgTestProgress) Reports_a_run_without_tests_as_empty_result(
+	t *T,
+) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/fx")
+	dir, _ := mod.Mk("fx")
+	dir.MkPkgTest("fx", []byte("func F() int { return 42 }\n"))
+	tp := &TestingPackage{abs: dir.Path(), Timeout: DefaultTimeout}
+
+	rr, err := tp.RunWithProgress(context.Background(), 0, nil)
+
+	t.FatalOn(err)
+	t.Not.True(rr.HasErr())
+	t.Eq(0, rr.Len())
+	t.True(rr.Passed())
+}
+
+func (s *PkgTestProgress) Is_canceled_with_its_context(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/fx")
+	dir, _ := mod.Mk("fx")
+	dir.MkPkgTest("fx", []byte("import (\n\t\"testing\"\n\t\"time\"\n)\n\n"+
+		"func TestHang(t *testing.T) { time.Sleep(time.Minute) }\n"))
+	tp := &TestingPackage{abs: dir.Path(), Timeout: DefaultTimeout}
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+
+	_, err := tp.RunWithProgress(ctx, 0, func(p *Progress) {
+		if p.Action == testjson.Run {
+			cancel()
+		}
+	})
+
+	t.ErrIs(err, ErrCanceled)
+	t.True(time.Since(start) < 30*time.Second)
+}
+
+func TestPkgTestProgress(t *testing.T) {
+	t.Parallel()
+	Run(&PkgTestProgress{}, t)
+}
@@ -80,6 +80,11 @@ const (
 	// a suit-test-line is not selectable.
 	SuiteTestLine
 
+	// Flaky sets the formattings for a passed test which failed at
+	// least once before it passed in a retry, i.e. an olive background
+	// and a black foreground.
+	Flaky
+
 	// ZeroLineMode indicates no other than default formattings for a
 	// line of a reporting component.
 	ZeroLineMod LineMask = 0
@@ -158,8 +163,11 @@ func (r *report) OnUpdate(e *lines.Env, data interface{}) {
 			}
 			lines.Print(e.LL(idx).At(0), []rune(content)[:indent])
 			w := e.LL(idx).At(indent)
-			if lm&Failed != 0 {
+			switch {
+			case lm&Failed != 0:
 				w = w.FG(lines.White).BG(lines.DarkRed)
+			case lm&Flaky != 0:
+				w = w.FG(lines.Black).BG(lines.Olive)
 			}
 			lines.Print(w, []rune(content)[indent:])
 			return
@@ -178,8 +186,11 @@ func (r *report) reportFocusable(
 	cc := strings.Split(content, lines.Filler)
 	lines.Print(e.LL(idx).At(0), []rune(cc[0][:indent]))
 	w := e.LL(idx).At(indent).AA(lines.Underline)
-	if lm&Failed != 0 {
+	switch {
+	case lm&Failed != 0:
 		w = w.FG(lines.White).BG(lines.DarkRed)
+	case lm&Flaky != 0:
+		w = w.FG(lines.Black).BG(lines.Olive)
 	}
 	lines.Print(w, []rune(cc[0][indent:]))
 	if len(cc) == 1 {
@@ -32,6 +32,12 @@ type Statuser struct {
 	// Failed is the number of failed tests
 	Failed int
 
+	// Flaky is the number of tests which passed after a failed attempt
+	Flaky int
+
+	// Queued is the number of test runs waiting to be started
+	Queued int
+
 	// Files is the number of code files
 	Files int
 
@@ -59,6 +65,10 @@ type statusBar struct {
 	nt int
 	// nf failed tests count
 	nf int
+	// nfl flaky tests count
+	nfl int
+	// nq queued test runs count
+	nq int
 	// ns source files count
 	nsr int
 	// nst source test files count
@@ -88,6 +98,8 @@ func (sb *statusBar) OnUpdate(e *lines.Env, data interface{}) {
 	sb.ns = s.Suites
 	sb.nt = s.Tests
 	sb.nf = s.Failed
+	sb.nfl = s.Flaky
+	sb.nq = s.Queued
 	sb.nsr = s.Files
 	sb.nst = s.TestFiles
 	sb.nc = s.Lines
@@ -98,17 +110,43 @@ func (sb *statusBar) OnUpdate(e *lines.Env, data interface{}) {
 
 const dfltStatus = "pkgs/suites: %d/%d; tests: %d/%d"
 
-const sourceStatsStatus = dfltStatus + "  source-stats: %d/%d %d/%d/%d"
+const flakyStatus = "; flaky: %d"
+
+const queuedStatus = "; queued: %d"
+
+const sourceStatsStatus = "  source-stats: %d/%d %d/%d/%d"
 
 func (sb *statusBar) str() string {
-	if sb.nsr > 0 {
-		return fmt.Sprintf(sourceStatsStatus,
-			sb.np, sb.ns, sb.nt, sb.nf,
-			sb.nsr, sb.nst, sb.nc, sb.nct, sb.nd)
+	return Statuser{
+		Packages: sb.np, Suites: sb.ns, Tests: sb.nt, Failed: sb.nf,
+		Flaky: sb.nfl, Queued: sb.nq, Files: sb.nsr, TestFiles: sb.nst,
+		Lines: sb.nc, TestLines: sb.nct, DocLines: sb.nd,
+	}.String()
+}
+
+// String returns the status as it is shown in the status bar.
+func (s Statuser) String() string {
+	if s.Str != "" {
+		return s.Str
+	}
+	str := fmt.Sprintf(dfltStatus, s.Packages, s.Suites, s.Tests, s.Failed)
+	if s.Flaky > 0 {
+		str += fmt.Sprintf(flakyStatus, s.Flaky)
+	}
+	if s.Queued > 0 {
+		str += fmt.Sprintf(queuedStatus, s.Queued)
 	}
-	return fmt.Sprintf(dfltStatus, sb.np, sb.ns, sb.nt, sb.nf)
+	if s.Files > 0 {
+		str += fmt.Sprintf(sourceStatsStatus,
+			s.Files, s.TestFiles, s.Lines, s.TestLines, s.DocLines)
+	}
+	return str
 }
 
+// IsFailing returns true iff the status reports failed tests or a
+// package error.
+func (s Statuser) IsFailing() bool { return s.Failed > 0 || s.HasError }
+
 func (sb *statusBar) bg() lines.Color {
 	if sb.nf > 0 || sb.hasError {
 		return lines.DarkRed
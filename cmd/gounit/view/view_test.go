@@ -99,6 +99,26 @@ func (s *AView) Updates_statusbar_with_given_numbers(t *T) {
 	t.Contains(tt.Screen(), fmt.Sprintf(dfltStatus, 1, 2, 5, 2))
 }
 
+func (s *AView) Updates_statusbar_with_flaky_tests_count(t *T) {
+	tt := NewFixture(t, 0, nil)
+	exp := Statuser{Packages: 1, Suites: 2, Tests: 5, Flaky: 1}
+
+	tt.UpdateStatus(exp)
+
+	t.Contains(tt.Screen(), fmt.Sprintf(
+		dfltStatus+flakyStatus, 1, 2, 5, 0, 1))
+}
+
+func (s *AView) Updates_statusbar_with_queued_test_runs_count(t *T) {
+	tt := NewFixture(t, 0, nil)
+	exp := Statuser{Packages: 1, Suites: 2, Tests: 5, Queued: 3}
+
+	tt.UpdateStatus(exp)
+
+	t.Contains(tt.Screen(), fmt.Sprintf(
+		dfltStatus+queuedStatus, 1, 2, 5, 0, 3))
+}
+
 func (s *AView) Status_has_green_background_if_not_failing(t *T) {
 	tt := NewFixture(t, 0, nil)
 	tt.UpdateStatus(Statuser{
@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gounit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// deadlineErr reports an expired deadline of a suite-test, an Init- or
+// a Finalize-method along with the stacks of its goroutines.
+const deadlineErr = "gounit: deadline of %v exceeded; goroutines:\n%s"
+
+// Context returns a context which is cancelled as soon as t's test ends
+// or the deadline of a suite implementing [SuiteDeadliner] expires.
+func (t *T) Context() context.Context {
+	if t.ctx == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.t.Cleanup(cancel)
+		t.ctx = ctx
+	}
+	return t.ctx
+}
+
+// Context returns a context which is cancelled as soon as the Init- or
+// Finalize-method st was passed to returns or the deadline of a suite
+// implementing [SuiteDeadliner] expires.
+func (st *S) Context() context.Context {
+	if st.ctx == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		st.t.Cleanup(cancel)
+		st.ctx = ctx
+	}
+	return st.ctx
+}
+
+// within runs given function f with a context which is cancelled after
+// f returned.  Is given duration d positive f is run in a worker
+// goroutine and its context is also cancelled after d elapsed.  A
+// worker which returns in time is joined; a cancellation of a T or S
+// running in it terminates the worker and is recorded for the calling
+// goroutine which then calls the actual canceler, e.g. testing.T's
+// FailNow.  Does the deadline expire the worker is abandoned, i.e.
+// within returns without waiting for f to return; given expired flag
+// is set and the failure message reporting the stacks of f's goroutine
+// and of the goroutines it spawned is returned.  NOTE the abandoned f
+// keeps running until it respects its context's cancellation; the
+// reporting of a T or S whose expired flag is set is dropped and its
+// cancellation terminates the worker.
+func within(
+	d time.Duration, expired *atomic.Bool, f func(context.Context),
+) (failure string) {
+	if d <= 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		f(ctx)
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	// done reports the stacks of the worker and of the goroutines it
+	// spawned if f returned after its context expired.
+	done, id := make(chan string, 1), make(chan string, 1)
+	go func() {
+		defer func() {
+			if ctx.Err() == nil {
+				done <- ""
+				return
+			}
+			done <- stacks(goroutineID())
+		}()
+		id <- goroutineID()
+		f(ctx)
+	}()
+	select {
+	case late := <-done: // join the worker
+		return expire(d, expired, late)
+	case <-ctx.Done():
+		select {
+		case late := <-done: // f returned in the meantime
+			return expire(d, expired, late)
+		default:
+		}
+		// abandon the worker
+		return expire(d, expired, stacks(<-id))
+	}
+}
+
+// expire sets given expired flag and returns the failure message of
+// given deadline d reporting given stacks of an expired worker; the
+// zero string is returned if there are no stacks.
+func expire(d time.Duration, expired *atomic.Bool, stacks string) string {
+	if stacks == "" {
+		return ""
+	}
+	expired.Store(true)
+	return fmt.Sprintf(deadlineErr, d, stacks)
+}
+
+// reGoroutine matches the header of a goroutine's stack trace while
+// reCreatedBy matches the id of the goroutine which created it.
+var (
+	reGoroutine = regexp.MustCompile(`^goroutine (\d+) `)
+	reCreatedBy = regexp.MustCompile(`\ncreated by .* in goroutine (\d+)`)
+)
+
+// goroutineID returns the id of the calling goroutine.
+func goroutineID() string {
+	buf := make([]byte, 64)
+	n := runtime.Stack(buf, false)
+	if m := reGoroutine.FindSubmatch(buf[:n]); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// stacks returns the stack traces of the goroutine with given id and
+// of the goroutines it created directly or indirectly.
+func stacks(id string) string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	type trace struct{ id, parent, stack string }
+	tt := []trace{}
+	for _, s := range strings.Split(string(buf), "\n\n") {
+		m := reGoroutine.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		t := trace{id: m[1], stack: s}
+		if m := reCreatedBy.FindStringSubmatch(s); m != nil {
+			t.parent = m[1]
+		}
+		tt = append(tt, t)
+	}
+	ids := map[string]bool{id: true}
+	for added := true; added; {
+		added = false
+		for _, t := range tt {
+			if !ids[t.id] && ids[t.parent] {
+				ids[t.id], added = true, true
+			}
+		}
+	}
+	ss := []string{}
+	for _, t := range tt {
+		if ids[t.id] {
+			ss = append(ss, t.stack)
+		}
+	}
+	return strings.Join(ss, "\n\n")
+}
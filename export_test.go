@@ -30,3 +30,6 @@ const FalseErr = notTrueErr
 
 // TrueErr default message for failed 'true'-assertion.
 const TrueErr = trueErr
+
+// DirEqErr default message for failed "DirEq"-assertion
+const DirEqErr = dirEqErr
@@ -10,6 +10,8 @@ require golang.org/x/exp v0.0.0-20230116083435-1de6713980de
 
 require github.com/slukits/lines v0.9.1
 
+require golang.org/x/sys v0.4.0
+
 require github.com/jackdoe/go-gpmctl v0.0.0-20221007100923-dc00b863cb22 // indirect
 
 require (
@@ -19,7 +21,6 @@ require (
 	github.com/mattn/go-runewidth v0.0.14 // indirect
 	github.com/rivo/uniseg v0.4.3 // indirect
 	github.com/slukits/ints v0.0.0-20221112103347-af0b55a6436b
-	golang.org/x/sys v0.4.0 // indirect
 	golang.org/x/term v0.4.0 // indirect
 	golang.org/x/text v0.6.0 // indirect
 )
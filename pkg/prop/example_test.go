@@ -0,0 +1,36 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package prop_test
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/slukits/gounit/pkg/prop"
+)
+
+func reverse(ii []int) []int {
+	rr := make([]int, 0, len(ii))
+	for i := len(ii) - 1; i >= 0; i-- {
+		rr = append(rr, ii[i])
+	}
+	return rr
+}
+
+func Example() {
+	f, err := prop.Run(prop.Config{Seed: 1}, func(ii []int) bool {
+		return reflect.DeepEqual(ii, reverse(reverse(ii)))
+	}, prop.SliceOf(prop.Int(-10, 10), 20))
+	fmt.Println(f == nil, err)
+
+	// the generator is derived from the property's argument type
+	f, err = prop.Run(prop.Config{Seed: 1}, func(ii []int) bool {
+		return reflect.DeepEqual(ii, reverse(ii))
+	})
+	fmt.Println(f, err)
+	// Output:
+	// true <nil>
+	// []int{0, -1} <nil>
+}
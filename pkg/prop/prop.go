@@ -0,0 +1,667 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+Package prop provides composable generators of random values and a
+runner which checks a property against generated values.  If a property
+fails the runner shrinks the failing input to a minimal counterexample.
+A property is a function whose arguments are provided by generators and
+which returns either a bool, an error or nothing, i.e. in the later case
+only a panic fails the property.
+
+Properties are usually checked through a gounit.T instance:
+
+	import (
+		"reflect"
+		"testing"
+
+		"github.com/slukits/gounit"
+		"github.com/slukits/gounit/pkg/prop"
+	)
+
+	type MySuite struct{ gounit.Suite }
+
+	func (s *MySuite) Reverse_is_its_own_inverse(t *gounit.T) {
+		t.Check(func(ii []int) bool {
+			return reflect.DeepEqual(ii, reverse(reverse(ii)))
+		}, prop.SliceOf(prop.Int(-10, 10), 20))
+	}
+
+	func TestMySuite(t *testing.T) { gounit.Run(&MySuite{}, t) }
+
+If no generators are given they are derived from the property's
+argument types by [Of].
+*/
+package prop
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+)
+
+// DefaultMaxLen is the maximal length of strings, slices and maps
+// generated by generators derived from types, see [Of].
+const DefaultMaxLen = 16
+
+// Generator generates random values of a particular type and provides
+// for a given value smaller candidates of the same type which are used
+// to shrink a failing input of a property to a minimal counterexample.
+type Generator interface {
+
+	// Type returns the type of generated values.
+	Type() reflect.Type
+
+	// Generate returns a new random value leveraging given random
+	// source.
+	Generate(*rand.Rand) interface{}
+
+	// Shrink returns for given value "smaller" values ordered from the
+	// smallest to the biggest; returned values are tried in the given
+	// order when a failing property input is shrunk.  Shrink returns
+	// nil if given value can't be shrunk any further.
+	Shrink(interface{}) []interface{}
+}
+
+// gen is the Generator implementation of this package.
+type gen struct {
+	typ      reflect.Type
+	generate func(*rand.Rand) reflect.Value
+	shrink   func(reflect.Value) []reflect.Value
+}
+
+func (g *gen) Type() reflect.Type { return g.typ }
+
+func (g *gen) Generate(r *rand.Rand) interface{} {
+	return g.generate(r).Interface()
+}
+
+func (g *gen) Shrink(v interface{}) []interface{} {
+	if g.shrink == nil {
+		return nil
+	}
+	var vv []interface{}
+	for _, s := range g.shrink(valueOf(g.typ, v)) {
+		vv = append(vv, s.Interface())
+	}
+	return vv
+}
+
+// valueOf returns the reflection value of given value of given type
+// which is the zero value of given type if given value is nil.
+func valueOf(typ reflect.Type, v interface{}) reflect.Value {
+	if v == nil {
+		return reflect.Zero(typ)
+	}
+	return reflect.ValueOf(v).Convert(typ)
+}
+
+// generate returns the reflection value of a value generated by given
+// generator.
+func generate(g Generator, r *rand.Rand) reflect.Value {
+	return valueOf(g.Type(), g.Generate(r))
+}
+
+// shrink returns the reflection values of the shrink candidates of
+// given generator for given value.
+func shrink(g Generator, v reflect.Value) []reflect.Value {
+	vv := []reflect.Value{}
+	for _, s := range g.Shrink(v.Interface()) {
+		vv = append(vv, valueOf(g.Type(), s))
+	}
+	return vv
+}
+
+// New returns a user defined generator whose generated values have the
+// type of given sample.  Given shrink function may be nil in which case
+// generated values are not shrunk.
+func New(
+	sample interface{},
+	generate func(*rand.Rand) interface{},
+	shrink func(interface{}) []interface{},
+) Generator {
+	typ := reflect.TypeOf(sample)
+	g := &gen{typ: typ, generate: func(r *rand.Rand) reflect.Value {
+		return valueOf(typ, generate(r))
+	}}
+	if shrink != nil {
+		g.shrink = func(v reflect.Value) []reflect.Value {
+			vv := []reflect.Value{}
+			for _, s := range shrink(v.Interface()) {
+				vv = append(vv, valueOf(typ, s))
+			}
+			return vv
+		}
+	}
+	return g
+}
+
+// Const returns a generator which always generates given value.
+func Const(v interface{}) Generator {
+	return New(v, func(*rand.Rand) interface{} { return v }, nil)
+}
+
+// OneOf returns a generator which generates one of given values whereas
+// the first value is considered the smallest.  OneOf panics if no
+// values are given or if given values are not all of the same type.
+func OneOf(vv ...interface{}) Generator {
+	if len(vv) == 0 {
+		panic("prop: one-of: no values given")
+	}
+	typ := reflect.TypeOf(vv[0])
+	for _, v := range vv[1:] {
+		if reflect.TypeOf(v) != typ {
+			panic(fmt.Sprintf("prop: one-of: type mismatch: %s != %T",
+				typ, v))
+		}
+	}
+	return New(vv[0], func(r *rand.Rand) interface{} {
+		return vv[r.Intn(len(vv))]
+	}, func(v interface{}) []interface{} {
+		for i, _v := range vv {
+			if reflect.DeepEqual(v, _v) {
+				return vv[:i]
+			}
+		}
+		return nil
+	})
+}
+
+// Bool returns a generator for bool values which shrink to false.
+func Bool() Generator { return boolOf(reflect.TypeOf(false)) }
+
+func boolOf(typ reflect.Type) Generator {
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			return reflect.ValueOf(r.Intn(2) == 1).Convert(typ)
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			if !v.Bool() {
+				return nil
+			}
+			return []reflect.Value{reflect.Zero(typ)}
+		},
+	}
+}
+
+// Int returns a generator for int values between given min and max
+// (inclusive).  Generated values shrink towards zero or the bound
+// closest to zero if zero is not in the range.  Int panics if min is
+// greater than max.
+func Int(min, max int) Generator {
+	return intOf(reflect.TypeOf(0), int64(min), int64(max))
+}
+
+// Int64 returns a generator for int64 values between given min and max
+// (inclusive), see [Int].
+func Int64(min, max int64) Generator {
+	return intOf(reflect.TypeOf(int64(0)), min, max)
+}
+
+func intOf(typ reflect.Type, min, max int64) Generator {
+	if min > max {
+		panic(fmt.Sprintf("prop: int: min %d > max %d", min, max))
+	}
+	target := int64(0)
+	switch {
+	case min > 0:
+		target = min
+	case max < 0:
+		target = max
+	}
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			v := reflect.New(typ).Elem()
+			v.SetInt(randInt64(r, min, max))
+			return v
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			vv := []reflect.Value{}
+			for _, i := range shrinkInt64(v.Int(), target) {
+				_v := reflect.New(typ).Elem()
+				_v.SetInt(i)
+				vv = append(vv, _v)
+			}
+			return vv
+		},
+	}
+}
+
+// randInt64 returns a random value in given range whereas the bounds
+// and zero are chosen with an increased probability since they are the
+// usual suspects for failing properties.
+func randInt64(r *rand.Rand, min, max int64) int64 {
+	switch r.Intn(10) {
+	case 0:
+		return min
+	case 1:
+		return max
+	case 2:
+		if min <= 0 && max >= 0 {
+			return 0
+		}
+	}
+	span := uint64(max) - uint64(min)
+	if span == math.MaxUint64 {
+		return int64(r.Uint64())
+	}
+	return int64(uint64(min) + r.Uint64()%(span+1))
+}
+
+// shrinkInt64 returns the candidates from given target towards given
+// value x, i.e. the target, halfway between target and x, ..., x-1 (if
+// target < x).
+func shrinkInt64(x, target int64) []int64 {
+	if x == target {
+		return nil
+	}
+	ii := []int64{target}
+	for d := x/2 - target/2; d != 0; d /= 2 {
+		if c := x - d; c != ii[len(ii)-1] && c != x {
+			ii = append(ii, c)
+		}
+	}
+	return ii
+}
+
+// Uint returns a generator for uint values between given min and max
+// (inclusive).  Generated values shrink towards min.  Uint panics if
+// min is greater than max.
+func Uint(min, max uint) Generator {
+	return uintOf(reflect.TypeOf(uint(0)), uint64(min), uint64(max))
+}
+
+func uintOf(typ reflect.Type, min, max uint64) Generator {
+	if min > max {
+		panic(fmt.Sprintf("prop: uint: min %d > max %d", min, max))
+	}
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			v := reflect.New(typ).Elem()
+			switch {
+			case r.Intn(10) == 0:
+				v.SetUint(min)
+			case r.Intn(10) == 0:
+				v.SetUint(max)
+			case max-min == math.MaxUint64:
+				v.SetUint(r.Uint64())
+			default:
+				v.SetUint(min + r.Uint64()%(max-min+1))
+			}
+			return v
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			x, vv := v.Uint(), []reflect.Value{}
+			if x == min {
+				return nil
+			}
+			_v := reflect.New(typ).Elem()
+			_v.SetUint(min)
+			vv = append(vv, _v)
+			for d := (x - min) / 2; d != 0; d /= 2 {
+				_v := reflect.New(typ).Elem()
+				_v.SetUint(x - d)
+				vv = append(vv, _v)
+			}
+			return vv
+		},
+	}
+}
+
+// Float64 returns a generator for float64 values between given min and
+// max.  Generated values shrink towards zero respectively the bound
+// closest to zero.  Float64 panics if min is greater than max.
+func Float64(min, max float64) Generator {
+	return floatOf(reflect.TypeOf(float64(0)), min, max)
+}
+
+func floatOf(typ reflect.Type, min, max float64) Generator {
+	if min > max {
+		panic(fmt.Sprintf("prop: float: min %v > max %v", min, max))
+	}
+	target := 0.0
+	switch {
+	case min > 0:
+		target = min
+	case max < 0:
+		target = max
+	}
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			v := reflect.New(typ).Elem()
+			v.SetFloat(min + r.Float64()*(max-min))
+			return v
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			x := v.Float()
+			if x == target {
+				return nil
+			}
+			vv := []reflect.Value{}
+			for _, c := range []float64{
+				target, math.Trunc(x), target + (x-target)/2,
+			} {
+				if c == x || c < min || c > max {
+					continue
+				}
+				_v := reflect.New(typ).Elem()
+				_v.SetFloat(c)
+				vv = append(vv, _v)
+			}
+			return vv
+		},
+	}
+}
+
+// Alphanumeric is the default alphabet of generated strings.
+const Alphanumeric = "abcdefghijklmnopqrstuvwxyz" +
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// String returns a generator for strings of at most given maximal
+// length of [Alphanumeric] runes.
+func String(maxLen int) Generator {
+	return StringOf(Alphanumeric, maxLen)
+}
+
+// StringOf returns a generator for strings of at most given maximal
+// length whose runes are taken from given alphabet.  Generated strings
+// shrink towards the empty string and towards the first rune of given
+// alphabet.  StringOf panics if given alphabet is empty.
+func StringOf(alphabet string, maxLen int) Generator {
+	return stringOf(reflect.TypeOf(""), alphabet, maxLen)
+}
+
+func stringOf(typ reflect.Type, alphabet string, maxLen int) Generator {
+	rr := []rune(alphabet)
+	if len(rr) == 0 {
+		panic("prop: string: empty alphabet")
+	}
+	str := func(s string) reflect.Value {
+		return reflect.ValueOf(s).Convert(typ)
+	}
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			b := strings.Builder{}
+			for i := r.Intn(maxLen + 1); i > 0; i-- {
+				b.WriteRune(rr[r.Intn(len(rr))])
+			}
+			return str(b.String())
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			s := []rune(v.String())
+			if len(s) == 0 {
+				return nil
+			}
+			vv := []reflect.Value{str("")}
+			if len(s) > 1 {
+				vv = append(vv,
+					str(string(s[:len(s)/2])), str(string(s[len(s)/2:])))
+			}
+			for i := range s {
+				vv = append(vv, str(string(s[:i])+string(s[i+1:])))
+			}
+			for i := range s {
+				if s[i] == rr[0] {
+					continue
+				}
+				_s := append([]rune{}, s...)
+				_s[i] = rr[0]
+				vv = append(vv, str(string(_s)))
+			}
+			return vv
+		},
+	}
+}
+
+// SliceOf returns a generator for slices of at most given maximal
+// length whose elements are generated by given generator.  Generated
+// slices shrink by removing elements and by shrinking elements.
+func SliceOf(elem Generator, maxLen int) Generator {
+	return sliceOf(reflect.SliceOf(elem.Type()), elem, maxLen)
+}
+
+func sliceOf(typ reflect.Type, elem Generator, maxLen int) Generator {
+	sub := func(v reflect.Value, i, j int) reflect.Value {
+		s := reflect.MakeSlice(typ, 0, j-i)
+		return reflect.AppendSlice(s, v.Slice(i, j))
+	}
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			n := r.Intn(maxLen + 1)
+			s := reflect.MakeSlice(typ, 0, n)
+			for i := 0; i < n; i++ {
+				s = reflect.Append(s, generate(elem, r))
+			}
+			return s
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			n := v.Len()
+			if n == 0 {
+				return nil
+			}
+			vv := []reflect.Value{reflect.MakeSlice(typ, 0, 0)}
+			if n > 1 {
+				vv = append(vv, sub(v, 0, n/2), sub(v, n/2, n))
+			}
+			for i := 0; i < n; i++ {
+				vv = append(vv, reflect.AppendSlice(
+					sub(v, 0, i), v.Slice(i+1, n)))
+			}
+			for i := 0; i < n; i++ {
+				for _, e := range shrink(elem, v.Index(i)) {
+					s := sub(v, 0, n)
+					s.Index(i).Set(e)
+					vv = append(vv, s)
+				}
+			}
+			return vv
+		},
+	}
+}
+
+// MapOf returns a generator for maps of at most given maximal length
+// whose keys and values are generated by given generators.  Generated
+// maps shrink by removing entries and by shrinking values.
+func MapOf(key, value Generator, maxLen int) Generator {
+	return mapOf(reflect.MapOf(key.Type(), value.Type()),
+		key, value, maxLen)
+}
+
+func mapOf(
+	typ reflect.Type, key, value Generator, maxLen int,
+) Generator {
+	cp := func(v reflect.Value, skip reflect.Value) reflect.Value {
+		m := reflect.MakeMap(typ)
+		iter := v.MapRange()
+		for iter.Next() {
+			if skip.IsValid() && iter.Key().Interface() == skip.Interface() {
+				continue
+			}
+			m.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return m
+	}
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			m := reflect.MakeMap(typ)
+			for i := r.Intn(maxLen + 1); i > 0; i-- {
+				m.SetMapIndex(generate(key, r), generate(value, r))
+			}
+			return m
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			if v.Len() == 0 {
+				return nil
+			}
+			vv := []reflect.Value{reflect.MakeMap(typ)}
+			kk := sortedKeys(v)
+			for _, k := range kk {
+				vv = append(vv, cp(v, k))
+			}
+			for _, k := range kk {
+				for _, s := range shrink(value, v.MapIndex(k)) {
+					m := cp(v, reflect.Value{})
+					m.SetMapIndex(k, s)
+					vv = append(vv, m)
+				}
+			}
+			return vv
+		},
+	}
+}
+
+// sortedKeys returns the keys of given map ordered by their string
+// representation which makes shrinking of maps deterministic.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	kk := m.MapKeys()
+	for i := 1; i < len(kk); i++ {
+		for j := i; j > 0 && fmt.Sprint(kk[j]) < fmt.Sprint(kk[j-1]); j-- {
+			kk[j], kk[j-1] = kk[j-1], kk[j]
+		}
+	}
+	return kk
+}
+
+// Struct returns a generator for structs of the type of given sample.
+// The exported fields of a generated struct are generated by given
+// generators mapped to field names or by generators derived from their
+// types (see [Of]) if there is no generator given for a field.
+// Unexported fields are left at their zero value.  Generated structs
+// shrink by shrinking their fields.  Struct panics if given sample is
+// not a struct or a derived generator can not be obtained.
+func Struct(sample interface{}, fields map[string]Generator) Generator {
+	typ := reflect.TypeOf(sample)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("prop: struct: not a struct: %T", sample))
+	}
+	return structOf(typ, fields)
+}
+
+func structOf(typ reflect.Type, fields map[string]Generator) Generator {
+	gg := make([]Generator, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if g, ok := fields[f.Name]; ok {
+			gg[i] = g
+			continue
+		}
+		gg[i] = Of(f.Type)
+	}
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			v := reflect.New(typ).Elem()
+			for i, g := range gg {
+				if g == nil {
+					continue
+				}
+				v.Field(i).Set(generate(g, r))
+			}
+			return v
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			vv := []reflect.Value{}
+			for i, g := range gg {
+				if g == nil {
+					continue
+				}
+				for _, s := range shrink(g, v.Field(i)) {
+					_v := reflect.New(typ).Elem()
+					_v.Set(v)
+					_v.Field(i).Set(s)
+					vv = append(vv, _v)
+				}
+			}
+			return vv
+		},
+	}
+}
+
+// PtrTo returns a generator for pointers to values generated by given
+// generator.  Every tenth generated pointer is nil.  Generated pointers
+// shrink towards nil and by shrinking the value they point to.
+func PtrTo(elem Generator) Generator {
+	return ptrOf(reflect.PtrTo(elem.Type()), elem)
+}
+
+func ptrOf(typ reflect.Type, elem Generator) Generator {
+	return &gen{
+		typ: typ,
+		generate: func(r *rand.Rand) reflect.Value {
+			if r.Intn(10) == 0 {
+				return reflect.Zero(typ)
+			}
+			p := reflect.New(typ.Elem())
+			p.Elem().Set(generate(elem, r))
+			return p
+		},
+		shrink: func(v reflect.Value) []reflect.Value {
+			if v.IsNil() {
+				return nil
+			}
+			vv := []reflect.Value{reflect.Zero(typ)}
+			for _, s := range shrink(elem, v.Elem()) {
+				p := reflect.New(typ.Elem())
+				p.Elem().Set(s)
+				vv = append(vv, p)
+			}
+			return vv
+		},
+	}
+}
+
+// For returns a generator for the type of given sample, see [Of].
+func For(sample interface{}) Generator {
+	return Of(reflect.TypeOf(sample))
+}
+
+// Of returns a generator derived from given type.  Supported are
+// booleans, integers, floats, strings, slices, maps, structs and
+// pointers whereas the later four need to have supported element
+// types.  Integers and floats are generated in their whole range,
+// strings, slices and maps have at most [DefaultMaxLen] elements.  Of
+// panics if given type is not supported.
+func Of(typ reflect.Type) Generator {
+	if typ == nil {
+		panic("prop: of: nil type")
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return boolOf(typ)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		bits := typ.Bits()
+		return intOf(typ, -1<<(bits-1), 1<<(bits-1)-1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return uintOf(typ, 0, math.MaxUint64>>(64-typ.Bits()))
+	case reflect.Float32:
+		return floatOf(typ, -math.MaxFloat32, math.MaxFloat32)
+	case reflect.Float64:
+		return floatOf(typ, -math.MaxFloat64/2, math.MaxFloat64/2)
+	case reflect.String:
+		return stringOf(typ, Alphanumeric, DefaultMaxLen)
+	case reflect.Slice:
+		return sliceOf(typ, Of(typ.Elem()), DefaultMaxLen)
+	case reflect.Map:
+		return mapOf(typ, Of(typ.Key()), Of(typ.Elem()), DefaultMaxLen)
+	case reflect.Struct:
+		return structOf(typ, nil)
+	case reflect.Ptr:
+		return ptrOf(typ, Of(typ.Elem()))
+	}
+	panic(fmt.Sprintf("prop: of: unsupported type: %s", typ))
+}
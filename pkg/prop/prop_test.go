@@ -0,0 +1,188 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package prop_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/prop"
+)
+
+type AGenerator struct{ Suite }
+
+func (s *AGenerator) SetUp(t *T) { t.Parallel() }
+
+func (s *AGenerator) Generates_ints_in_given_range(t *T) {
+	g, r := prop.Int(-3, 5), rand.New(rand.NewSource(42))
+	for i := 0; i < 200; i++ {
+		v := g.Generate(r).(int)
+		t.FatalIfNot(t.True(v >= -3 && v <= 5))
+	}
+}
+
+func (s *AGenerator) Shrinks_ints_towards_zero(t *T) {
+	t.Eq([]interface{}{0, 50, 75, 88, 94, 97, 99},
+		prop.Int(-100, 100).Shrink(100))
+	t.Eq([]interface{}{3, 5, 6}, prop.Int(3, 10).Shrink(7))
+	t.True(prop.Int(-10, 10).Shrink(0) == nil)
+}
+
+func (s *AGenerator) Shrinks_strings_towards_empty_string(t *T) {
+	ss := prop.StringOf("ab", 5).Shrink("bb")
+	t.FatalIfNot(t.True(len(ss) > 0))
+	t.Eq("", ss[0])
+	t.True(prop.String(5).Shrink("") == nil)
+}
+
+func (s *AGenerator) Generates_slices_of_at_most_given_length(t *T) {
+	g, r := prop.SliceOf(prop.Bool(), 3), rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		t.FatalIfNot(t.True(len(g.Generate(r).([]bool)) <= 3))
+	}
+}
+
+func (s *AGenerator) Generates_maps_of_at_most_given_length(t *T) {
+	g := prop.MapOf(prop.String(3), prop.Int(0, 3), 3)
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		t.FatalIfNot(t.True(len(g.Generate(r).(map[string]int)) <= 3))
+	}
+}
+
+type point struct {
+	X, Y   int
+	Label  string
+	hidden int
+}
+
+func (s *AGenerator) Generates_structs_with_given_field_generators(t *T) {
+	g := prop.Struct(point{}, map[string]prop.Generator{
+		"X": prop.Const(42)})
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 20; i++ {
+		p := g.Generate(r).(point)
+		t.FatalIfNot(t.True(p.X == 42 && p.hidden == 0))
+	}
+}
+
+func (s *AGenerator) Is_derived_from_types(t *T) {
+	r := rand.New(rand.NewSource(42))
+	for _, v := range []interface{}{
+		int8(0), uint16(0), float32(0), "", []int{}, map[string]bool{},
+		point{}, &point{},
+	} {
+		t.Eq(reflect.TypeOf(v), prop.For(v).Type())
+		t.Eq(reflect.TypeOf(v), reflect.TypeOf(prop.For(v).Generate(r)))
+	}
+}
+
+func (s *AGenerator) Derivation_panics_for_unsupported_types(t *T) {
+	t.Panics(func() { prop.For(make(chan int)) })
+}
+
+func (s *AGenerator) May_be_user_defined(t *T) {
+	even := prop.New(0, func(r *rand.Rand) interface{} {
+		return r.Intn(50) * 2
+	}, func(v interface{}) []interface{} {
+		if v.(int) == 0 {
+			return nil
+		}
+		return []interface{}{0, v.(int) - 2}
+	})
+	f, err := prop.Run(prop.Config{Seed: 42}, func(i int) bool {
+		return i%2 == 0 && i < 10
+	}, even)
+	t.FatalOn(err)
+	t.FatalIfNot(t.True(f != nil))
+	t.Eq([]interface{}{10}, f.Shrunk)
+}
+
+func TestAGenerator(t *testing.T) {
+	t.Parallel()
+	Run(&AGenerator{}, t)
+}
+
+type ARun struct{ Suite }
+
+func (s *ARun) SetUp(t *T) { t.Parallel() }
+
+func (s *ARun) Reports_nothing_for_holding_property(t *T) {
+	f, err := prop.Run(prop.Config{}, func(a, b int) bool {
+		return a+b == b+a
+	}, prop.Int(-100, 100), prop.Int(-100, 100))
+	t.FatalOn(err)
+	t.True(f == nil)
+}
+
+func (s *ARun) Shrinks_failing_input_to_minimal_counterexample(t *T) {
+	f, err := prop.Run(prop.Config{Seed: 1}, func(ii []int) bool {
+		for _, i := range ii {
+			if i >= 10 {
+				return false
+			}
+		}
+		return true
+	}, prop.SliceOf(prop.Int(0, 1000), 20))
+	t.FatalOn(err)
+	t.FatalIfNot(t.True(f != nil))
+	t.Eq([]interface{}{[]int{10}}, f.Shrunk)
+	t.Eq(int64(1), f.Seed)
+}
+
+func (s *ARun) Reproduces_failure_with_same_seed(t *T) {
+	property := func(s string) bool { return !strings.Contains(s, "x") }
+	f1, err := prop.Run(prop.Config{Seed: 7}, property)
+	t.FatalOn(err)
+	f2, err := prop.Run(prop.Config{Seed: 7}, property)
+	t.FatalOn(err)
+	t.FatalIfNot(t.True(f1 != nil && f2 != nil))
+	t.Eq(f1.Input, f2.Input)
+	t.Eq(f1.Check, f2.Check)
+	t.Eq([]interface{}{"x"}, f1.Shrunk)
+}
+
+func (s *ARun) Reports_errors_and_panics_of_property(t *T) {
+	f, err := prop.Run(prop.Config{}, func(i uint8) error {
+		if i > 3 {
+			return errors.New("too big")
+		}
+		return nil
+	})
+	t.FatalOn(err)
+	t.FatalIfNot(t.True(f != nil))
+	t.ErrMatched(f.Err, "too big")
+	t.Eq([]interface{}{uint8(4)}, f.Shrunk)
+
+	f, err = prop.Run(prop.Config{}, func(b bool) {
+		if b {
+			panic("true")
+		}
+	})
+	t.FatalOn(err)
+	t.FatalIfNot(t.True(f != nil))
+	t.ErrMatched(f.Err, "panic: true")
+}
+
+func (s *ARun) Fails_for_unusable_properties(t *T) {
+	_, err := prop.Run(prop.Config{}, 42)
+	t.ErrIs(err, prop.ErrProperty)
+	_, err = prop.Run(prop.Config{}, func(int) int { return 0 })
+	t.ErrIs(err, prop.ErrProperty)
+	_, err = prop.Run(prop.Config{}, func(int) bool { return true },
+		prop.String(3))
+	t.ErrIs(err, prop.ErrProperty)
+	_, err = prop.Run(prop.Config{}, func(chan int) bool { return true })
+	t.ErrIs(err, prop.ErrProperty)
+}
+
+func TestARun(t *testing.T) {
+	t.Parallel()
+	Run(&ARun{}, t)
+}
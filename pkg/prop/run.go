@@ -0,0 +1,251 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package prop
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultChecks is the number of generated inputs a property is
+// checked against if [Config.Checks] is not set.
+const DefaultChecks = 100
+
+// MaxShrinks bounds the number of successful shrink steps of a failing
+// input.
+const MaxShrinks = 1000
+
+// ErrProperty is returned by [Run] if a given property or given
+// generators are not usable.
+var ErrProperty = errors.New("prop: property")
+
+// Config parameterizes a [Run] of a property.
+type Config struct {
+
+	// Checks is the number of generated inputs a property is checked
+	// against; defaults to DefaultChecks.
+	Checks int
+
+	// Seed of the random source generators generate their values
+	// from.  If zero a time based seed is chosen.  A run with the seed
+	// of a failed run reproduces the failure.
+	Seed int64
+}
+
+// Failure reports a property which doesn't hold.
+type Failure struct {
+
+	// Seed is the seed the failing run was started with.
+	Seed int64
+
+	// Check is the number of the check which failed starting at 1.
+	Check int
+
+	// Input is the generated input the property failed for.
+	Input []interface{}
+
+	// Shrunk is the minimal input found by shrinking Input for which
+	// the property still fails.
+	Shrunk []interface{}
+
+	// Shrinks is the number of successful shrink steps.
+	Shrinks int
+
+	// Err is the error or panic value reported by the property for the
+	// shrunk input; it is nil if the property returned false.
+	Err error
+}
+
+// String returns given failure's shrunk input.
+func (f *Failure) String() string { return Format(f.Shrunk) }
+
+// Format returns given input as comma separated list of their go
+// representations.
+func Format(input []interface{}) string {
+	ss := []string{}
+	for _, v := range input {
+		ss = append(ss, fmt.Sprintf("%#v", v))
+	}
+	return strings.Join(ss, ", ")
+}
+
+// Run checks given property against values generated by given
+// generators.  If no generators are given they are derived from the
+// property's argument types (see [Of]).  A property must be a function
+// which returns either a bool, an error or nothing.  A property fails
+// if it returns false, a non-nil error or if it panics.  In case of a
+// failing property the failing input is shrunk to a minimal
+// counterexample and a Failure is returned; otherwise nil.  Run returns
+// a wrapped [ErrProperty] error if given property is not a function as
+// described above or if the generators don't match the property's
+// arguments.
+func Run(
+	cfg Config, property interface{}, gg ...Generator,
+) (*Failure, error) {
+	p, err := newProp(property, gg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Checks <= 0 {
+		cfg.Checks = DefaultChecks
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(cfg.Seed))
+	for i := 1; i <= cfg.Checks; i++ {
+		in := p.generate(r)
+		if p.check(in) == nil {
+			continue
+		}
+		shrunk, n := p.shrink(in)
+		return &Failure{
+			Seed:    cfg.Seed,
+			Check:   i,
+			Input:   interfaces(in),
+			Shrunk:  interfaces(shrunk),
+			Shrinks: n,
+			Err:     errOf(p.check(shrunk)),
+		}, nil
+	}
+	return nil, nil
+}
+
+// errFalse is reported by a property check if a property returned
+// false.
+var errFalse = errors.New("false")
+
+func errOf(err error) error {
+	if err == errFalse {
+		return nil
+	}
+	return err
+}
+
+type prop struct {
+	fn reflect.Value
+	gg []Generator
+}
+
+var errType = reflect.TypeOf((*error)(nil)).Elem()
+
+func newProp(property interface{}, gg []Generator) (*prop, error) {
+	fn := reflect.ValueOf(property)
+	if fn.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%w: not a function: %T",
+			ErrProperty, property)
+	}
+	typ := fn.Type()
+	if typ.IsVariadic() {
+		return nil, fmt.Errorf("%w: variadic", ErrProperty)
+	}
+	switch {
+	case typ.NumOut() == 0:
+	case typ.NumOut() == 1 && typ.Out(0).Kind() == reflect.Bool:
+	case typ.NumOut() == 1 && typ.Out(0) == errType:
+	default:
+		return nil, fmt.Errorf(
+			"%w: must return bool, error or nothing", ErrProperty)
+	}
+	if len(gg) == 0 {
+		for i := 0; i < typ.NumIn(); i++ {
+			g, err := derive(typ.In(i))
+			if err != nil {
+				return nil, err
+			}
+			gg = append(gg, g)
+		}
+	}
+	if len(gg) != typ.NumIn() {
+		return nil, fmt.Errorf("%w: %d arguments but %d generators",
+			ErrProperty, typ.NumIn(), len(gg))
+	}
+	for i, g := range gg {
+		if !g.Type().AssignableTo(typ.In(i)) {
+			return nil, fmt.Errorf(
+				"%w: argument %d: %s not assignable to %s",
+				ErrProperty, i+1, g.Type(), typ.In(i))
+		}
+	}
+	return &prop{fn: fn, gg: gg}, nil
+}
+
+// derive returns the generator for given type or an error if Of panics.
+func derive(typ reflect.Type) (g Generator, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrProperty, r)
+		}
+	}()
+	return Of(typ), nil
+}
+
+func (p *prop) generate(r *rand.Rand) []reflect.Value {
+	in := []reflect.Value{}
+	for _, g := range p.gg {
+		in = append(in, generate(g, r))
+	}
+	return in
+}
+
+// check calls the property with given input and returns nil iff the
+// property holds.
+func (p *prop) check(in []reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	out := p.fn.Call(in)
+	if len(out) == 0 {
+		return nil
+	}
+	if out[0].Kind() == reflect.Bool {
+		if out[0].Bool() {
+			return nil
+		}
+		return errFalse
+	}
+	if out[0].IsNil() {
+		return nil
+	}
+	return out[0].Interface().(error)
+}
+
+// shrink greedily replaces the arguments of given failing input by the
+// first of their shrink candidates for which the property still fails
+// until there are no such candidates left or MaxShrinks is reached.
+func (p *prop) shrink(in []reflect.Value) ([]reflect.Value, int) {
+	in = append([]reflect.Value{}, in...)
+	n := 0
+	for shrunk := true; shrunk && n < MaxShrinks; {
+		shrunk = false
+		for i, g := range p.gg {
+			for _, c := range shrink(g, in[i]) {
+				_in := append([]reflect.Value{}, in...)
+				_in[i] = c
+				if p.check(_in) == nil {
+					continue
+				}
+				in, shrunk = _in, true
+				n++
+				break
+			}
+		}
+	}
+	return in, n
+}
+
+func interfaces(vv []reflect.Value) []interface{} {
+	ii := []interface{}{}
+	for _, v := range vv {
+		ii = append(ii, v.Interface())
+	}
+	return ii
+}
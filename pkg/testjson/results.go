@@ -0,0 +1,494 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package testjson
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Results reports the results of a test run's go Test* functions by
+// their packages and names whereas the results of sub-tests are
+// reported by their parent test.  Sub-tests are reported up to a depth
+// of two, i.e. deeper nested sub-tests are reported as sub-tests of
+// the second level with a slash separated name.
+type Results struct {
+	pp    map[string]*pkgResults
+	order []string
+}
+
+// pkgResults are the results of a package's go Test* functions.
+type pkgResults struct {
+	rr    map[string]*TestResult
+	order []string
+}
+
+// NewResults returns an empty Results instance to which events may be
+// added.
+func NewResults() *Results {
+	return &Results{pp: map[string]*pkgResults{}}
+}
+
+// Packages returns the import paths of the packages reported by given
+// results r in the order they were started.
+func (r *Results) Packages() []string {
+	return append([]string{}, r.order...)
+}
+
+// Package returns the results of the package with given import path;
+// nil if there is none.
+func (r *Results) Package(path string) *Results {
+	p, ok := r.pp[path]
+	if !ok {
+		return nil
+	}
+	return &Results{pp: map[string]*pkgResults{path: p},
+		order: []string{path}}
+}
+
+// Of returns the result of the go Test* function with given name of
+// the first package reporting it; nil if there is none.  Use
+// [Results.Package] to get a test's result of a particular package.
+func (r *Results) Of(test string) *TestResult {
+	for _, p := range r.order {
+		if t, ok := r.pp[p].rr[test]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// For calls back for each result of a go Test* function in the order
+// they were started by their packages.
+func (r *Results) For(cb func(*TestResult)) {
+	for _, p := range r.order {
+		for _, n := range r.pp[p].order {
+			cb(r.pp[p].rr[n])
+		}
+	}
+}
+
+// Len reports the number of go Test* functions.
+func (r *Results) Len() int {
+	n := 0
+	for _, p := range r.pp {
+		n += len(p.rr)
+	}
+	return n
+}
+
+// Passed returns true iff all go Test* functions passed.
+func (r *Results) Passed() bool {
+	passed := true
+	r.For(func(t *TestResult) {
+		if !t.Passed {
+			passed = false
+		}
+	})
+	return passed
+}
+
+// HasPanic returns the name and output of the first found test which
+// panicked and true; false if no test panicked.
+func (r *Results) HasPanic() (string, bool) {
+	err := ""
+	r.For(func(t *TestResult) {
+		if err == "" {
+			err = t.panicked()
+		}
+	})
+	return err, err != ""
+}
+
+// panicked returns the name and output of the first found test of
+// given test result t which panicked; the zero string if none did.
+func (t *TestResult) panicked() string {
+	if t.Panics {
+		return t.panicErr()
+	}
+	err := ""
+	t.For(func(sr *SubResult) {
+		if err != "" || (!sr.Panics && !sr.HasSubs()) {
+			return
+		}
+		if sr.Panics {
+			err = sr.panicErr()
+			return
+		}
+		sr.For(func(sr *SubResult) {
+			if err != "" || !sr.Panics {
+				return
+			}
+			err = sr.panicErr()
+		})
+	})
+	return err
+}
+
+// passSubSubs it seems that go test passing sub-tests having sub-tests
+// them self is not reporting as passing; hence we make them pass if all
+// their sub-tests pass.
+func (r *Results) passSubSubs() {
+	r.For(func(t *TestResult) { t.passSubSubs() })
+}
+
+func (t *TestResult) passSubSubs() {
+	if !t.HasSubs() {
+		return
+	}
+	t.For(func(sr *SubResult) {
+		if !sr.HasSubs() {
+			return
+		}
+		sr.Passed = true
+		sr.For(func(s *SubResult) {
+			if sr.Passed && s.Passed {
+				return
+			}
+			sr.Passed = false
+		})
+	})
+}
+
+var reSkip = regexp.MustCompile(`^\s*(===|---)`)
+
+// Add adds given event to given results r.  Events without a test are
+// ignored.
+func (r *Results) Add(e *Event) {
+	if e.Test == "" {
+		return
+	}
+	p, ok := r.pp[e.Package]
+	if !ok {
+		p = &pkgResults{rr: map[string]*TestResult{}}
+		r.pp[e.Package] = p
+		r.order = append(r.order, e.Package)
+	}
+	p.add(e)
+}
+
+func (r *pkgResults) add(e *Event) {
+	rslt := r.get(e.Test)
+	switch e.Action {
+	case Run:
+		if rslt.Start.IsZero() || e.Time.Before(rslt.Start) {
+			rslt.Start = e.Time
+		}
+	case Pass:
+		rslt.Passed = true
+		rslt.End = e.Time
+		if !strings.Contains(e.Test, "/") {
+			r.rr[e.Test].passSubSubs()
+		}
+	case Fail:
+		rslt.End = e.Time
+		if !strings.Contains(e.Test, "/") {
+			r.rr[e.Test].passSubSubs()
+		}
+	case Skip:
+		rslt.Passed = true
+		rslt.Skipped = true
+	case Output:
+		r.addOutput(e, rslt)
+	}
+}
+
+func (r *pkgResults) addOutput(e *Event, rslt *Result) {
+	if reSkip.MatchString(e.Output) {
+		if rslt.inRace {
+			rslt.inRace = false
+		}
+		return
+	}
+	if (strings.HasPrefix(e.Output, "panic:") ||
+		strings.HasPrefix(e.Output, "\tpanic:")) && !rslt.Panics {
+
+		rslt.Panics = true
+	}
+	output := e.Output
+	if strings.Contains(output, FlakyPrefix) {
+		rslt.Flaky = true
+		output = strings.Replace(output, FlakyPrefix+" ", "", 1)
+	}
+	if strings.Contains(output, InitPrefix) {
+		tr, ok := r.rr[e.Test]
+		if !ok {
+			return
+		}
+		tr.InitOut = appendLines(tr.InitOut,
+			strings.Replace(output, InitPrefix, "", 1))
+		return
+	}
+	if strings.Contains(output, FinalPrefix) {
+		tr, ok := r.rr[e.Test]
+		if !ok {
+			return
+		}
+		tr.FinalizeOut = appendLines(tr.FinalizeOut,
+			strings.Replace(output, FinalPrefix, "", 1))
+		return
+	}
+	rslt.Output = appendLines(rslt.Output, output)
+	if strings.Contains(output, "WARNING: DATA RACE") {
+		rslt.inRace = true
+		rslt.Passed = false
+	}
+}
+
+// appendLines appends given output's trimmed non-empty lines to given
+// lines.
+func appendLines(ll []string, output string) []string {
+	for _, s := range strings.Split(output, "\n") {
+		if s == "" {
+			continue
+		}
+		ll = append(ll, strings.TrimSpace(s))
+	}
+	return ll
+}
+
+func (r *pkgResults) get(testName string) *Result {
+	path := strings.SplitN(testName, "/", 3)
+	root, ok := r.rr[path[0]]
+	if !ok {
+		root = &TestResult{Result: &Result{Name: path[0]}}
+		r.rr[path[0]] = root
+		r.order = append(r.order, path[0])
+	}
+	if len(path) == 1 {
+		return root.Result
+	}
+	rslt := root.subs.get(path[1])
+	if rslt == nil {
+		rslt = root.subs.add(path[1])
+	}
+	if len(path) == 2 {
+		return rslt.Result
+	}
+	final := rslt.subs.get(path[2])
+	if final == nil {
+		return rslt.subs.add(path[2]).Result
+	}
+	return final.Result
+}
+
+// Result instance is embedded in a TestResult or SubResult and
+// expresses their commonalities.  There are two result types needed
+// because a TestResult may represent a test suite which in turn may
+// report test logs of the suites Init- or Finalize-method.  While
+// SubResult instances can't have this.
+type Result struct {
+	Passed  bool
+	Skipped bool
+	Panics  bool
+
+	// Flaky is true iff a test passed after at least one failed
+	// attempt, see gounit.SuiteRetrier.
+	Flaky bool
+
+	inRace bool
+	Output []string
+	Start  time.Time
+	End    time.Time
+	Name   string
+	subs   subResults
+}
+
+func (r *Result) panicErr() string {
+	return strings.Join(append([]string{r.Name}, r.Output...), "\n")
+}
+
+// Len is the number of executed test comprising given test result.
+// I.e. it is either 1 given result has no sub test results or the
+// number of executed sub tests.  I.e. tests having sub tests are not
+// counted.
+func (r *Result) Len() int {
+	if len(r.subs) == 0 {
+		return 1
+	}
+	n := 0
+	for _, s := range r.subs {
+		n += s.Len()
+	}
+	return n
+}
+
+// HasSubs allows to discriminate go-tests with one sub-test from a
+// single go-test.
+func (r *Result) HasSubs() bool { return len(r.subs) > 0 }
+
+// String returns the human readable name of given result's test, see
+// [HumanReadable].
+func (r *Result) String() string { return HumanReadable(r.Name) }
+
+// LenFailed returns the number of failed tests which is only
+// interesting in case of sub results otherwise a Result's Passed
+// property could be consulted.
+func (r *Result) LenFailed() int {
+	if len(r.subs) == 0 {
+		if r.Passed {
+			return 0
+		}
+		return 1
+	}
+	n := 0
+	for _, s := range r.subs {
+		n += s.LenFailed()
+	}
+	return n
+}
+
+// LenFlaky returns the number of tests which passed only after at
+// least one failed attempt.
+func (r *Result) LenFlaky() int {
+	if len(r.subs) == 0 {
+		if r.Passed && r.Flaky {
+			return 1
+		}
+		return 0
+	}
+	n := 0
+	for _, s := range r.subs {
+		n += s.LenFlaky()
+	}
+	return n
+}
+
+// For calls back for each sub test result of a test result.  I.e. in
+// case of a suite runner for each suite test.  Since it never
+// occurred to me to nest tests deeper than that the support for this
+// use case is rather rudimentary see [Result.Descend].
+func (r *Result) For(cb func(*SubResult)) {
+	for _, s := range r.subs {
+		cb(s)
+	}
+}
+
+// ForOrdered calls back for each sub test result of a test result
+// ordered by their names.
+func (r *Result) ForOrdered(cb func(*SubResult)) {
+	sort.Slice(r.subs, func(i, j int) bool {
+		return r.subs[i].Name < r.subs[j].Name
+	})
+	for _, s := range r.subs {
+		cb(s)
+	}
+}
+
+// Of returns the result of the sub test with given name; nil if there
+// is none.
+func (r *Result) Of(test string) *SubResult {
+	return r.subs.get(test)
+}
+
+// Descend provides a depth first traversing of a sub test result having
+// itself sub test results and so on.
+func (r *Result) Descend(sr *SubResult, cb func(parent, sr *SubResult)) {
+	sr.For(func(_sr *SubResult) {
+		cb(sr, _sr)
+		_sr.Descend(_sr, cb)
+	})
+}
+
+// TestResult indicates if a test has passed and what output it has
+// generated.
+type TestResult struct {
+	*Result
+
+	// InitOut reports the output of a test suites Init-method.
+	InitOut []string
+
+	// FinalizeOut reports the output of a test suites Finalize-method.
+	FinalizeOut []string
+}
+
+type subResults []*SubResult
+
+func (sr *subResults) get(test string) *SubResult {
+	for _, sr := range *sr {
+		if sr.Name != test {
+			continue
+		}
+		return sr
+	}
+	return nil
+}
+
+func (sr *subResults) add(test string) *SubResult {
+	_sr := &SubResult{Result: &Result{Name: test}}
+	*sr = append(*sr, _sr)
+	return _sr
+}
+
+// A SubResult of a run sub test is reported by a Result instance r:
+//
+//	r.For(func(sr *SubResult) {
+//	    // do some thing with sub test result
+//	})
+type SubResult struct {
+	*Result
+}
+
+var (
+	camelRe   = regexp.MustCompile(`\p{Lu}+[0-9.,!\- ]*`)
+	endsInNum = regexp.MustCompile(`\p{Lu}+[0-9.,!\- ]+`)
+	brokenEnd = regexp.MustCompile(`\p{Lu} \p{Ll}$`)
+)
+
+// HumanReadable turns given test name into a human readable sentence,
+// e.g. "TestHumanReadable" into "human readable" or
+// "Doesnt_change_its_input" into "doesn't change its input".
+func HumanReadable(name string) string {
+	if strings.Contains(name, "_") {
+		name = strings.ReplaceAll(name, "_", " ")
+		for i, c := range name {
+			name = string(unicode.ToLower(c)) + name[i+1:]
+			break
+		}
+	}
+	return apostrophe(camelCaseToHuman(name))
+}
+
+func apostrophe(name string) string {
+	name = strings.ReplaceAll(name, " s ", "'s ")
+	name = strings.ReplaceAll(name, "dont", "don't")
+	name = strings.ReplaceAll(name, "doesnt", "doesn't")
+	name = strings.ReplaceAll(name, "havent", "haven't")
+	name = strings.ReplaceAll(name, "hasnt", "hasn't")
+	name = strings.ReplaceAll(name, "isnt", "isn't")
+	return name
+}
+
+func camelCaseToHuman(str string) string {
+	str = strings.TrimSpace(camelRe.ReplaceAllStringFunc(
+		str, func(s string) string {
+			if len(s) == 1 {
+				return " " + strings.ToLower(s)
+			}
+			if endsInNum.MatchString(s) {
+				return " " + s
+			}
+			return " " + s[:len(s)-1] + " " + strings.ToLower(string(s[len(s)-1]))
+		}))
+	str = strings.ReplaceAll(str, "  ", " ")
+	str = brokenEnd.ReplaceAllStringFunc(str, func(s string) string {
+		prefix := rune(0)
+		for i, r := range s {
+			if i == 0 {
+				prefix = r
+				continue
+			}
+			if r == ' ' {
+				continue
+			}
+			return string(prefix) + strings.ToUpper(string(r))
+		}
+		return s
+	})
+	return strings.TrimPrefix(str, "test ")
+}
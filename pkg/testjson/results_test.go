@@ -0,0 +1,224 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package testjson_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/testjson"
+)
+
+type RunResults struct{ Suite }
+
+func (s *RunResults) SetUp(t *T) { t.Parallel() }
+
+// events returns for given tuples of action, test and output a go test
+// json output.
+func events(tt ...[3]string) []byte {
+	ll := []string{}
+	for _, t := range tt {
+		ll = append(ll, fmt.Sprintf(`{"Time":"2022-10-18T14:09:08Z",`+
+			`"Action":%q,"Package":"fx","Test":%q,"Output":%q,`+
+			`"Elapsed":0}`, t[0], t[1], t[2]))
+	}
+	return []byte(strings.Join(ll, "\n"))
+}
+
+func (s *RunResults) Mark_test_passed_after_a_retry_as_flaky(t *T) {
+	rr, err := testjson.Unmarshal(events(
+		[3]string{testjson.Run, "TestSuite", ""},
+		[3]string{testjson.Run, "TestSuite/Flaky", ""},
+		[3]string{testjson.Output, "TestSuite/Flaky",
+			"    fx_test.go:5: attempt 1/2: failed\n"},
+		[3]string{testjson.Output, "TestSuite/Flaky",
+			"    suite.go:278: " + FlakyPrefix +
+				" passed at attempt 2 of 2\n"},
+		[3]string{testjson.Pass, "TestSuite/Flaky", ""},
+		[3]string{testjson.Run, "TestSuite/Stable", ""},
+		[3]string{testjson.Pass, "TestSuite/Stable", ""},
+		[3]string{testjson.Pass, "TestSuite", ""},
+	))
+	t.FatalOn(err)
+
+	r := rr.Of("TestSuite")
+	t.Eq(1, r.LenFlaky())
+	t.Eq(0, r.LenFailed())
+	r.For(func(sr *testjson.SubResult) {
+		switch sr.Name {
+		case "Flaky":
+			t.True(sr.Flaky)
+			t.Eq("fx_test.go:5: attempt 1/2: failed", sr.Output[0])
+			t.Eq("suite.go:278: passed at attempt 2 of 2", sr.Output[1])
+		default:
+			t.Not.True(sr.Flaky)
+		}
+	})
+}
+
+func (s *RunResults) Dont_count_failing_flaky_test_as_flaky(t *T) {
+	rr, err := testjson.Unmarshal(events(
+		[3]string{testjson.Run, "TestSuite", ""},
+		[3]string{testjson.Run, "TestSuite/Flaky", ""},
+		[3]string{testjson.Output, "TestSuite/Flaky",
+			"    suite.go:278: " + FlakyPrefix + " passed\n"},
+		[3]string{testjson.Fail, "TestSuite/Flaky", ""},
+		[3]string{testjson.Fail, "TestSuite", ""},
+	))
+	t.FatalOn(err)
+
+	t.Eq(0, rr.Of("TestSuite").LenFlaky())
+}
+
+func (s *RunResults) Report_suite_init_and_finalize_logs(t *T) {
+	rr, err := testjson.Unmarshal(events(
+		[3]string{testjson.Run, "TestSuite", ""},
+		[3]string{testjson.Output, "TestSuite",
+			"    suite.go:5: " + InitPrefix + "init-log\n"},
+		[3]string{testjson.Output, "TestSuite",
+			"    suite.go:7: " + FinalPrefix + "final-log\n"},
+		[3]string{testjson.Pass, "TestSuite", ""},
+	))
+	t.FatalOn(err)
+
+	t.Eq([]string{"suite.go:5: init-log"}, rr.Of("TestSuite").InitOut)
+	t.Eq([]string{"suite.go:7: final-log"},
+		rr.Of("TestSuite").FinalizeOut)
+	t.Eq(0, len(rr.Of("TestSuite").Output))
+}
+
+func (s *RunResults) Report_the_first_panicking_sub_test(t *T) {
+	rr, err := testjson.Unmarshal(events(
+		[3]string{testjson.Run, "TestA", ""},
+		[3]string{testjson.Pass, "TestA", ""},
+		[3]string{testjson.Run, "TestB", ""},
+		[3]string{testjson.Run, "TestB/sub", ""},
+		[3]string{testjson.Output, "TestB/sub", "panic: boom\n"},
+		[3]string{testjson.Fail, "TestB/sub", ""},
+		[3]string{testjson.Fail, "TestB", ""},
+	))
+	t.FatalOn(err)
+
+	msg, ok := rr.HasPanic()
+	t.True(ok)
+	t.Eq("sub\npanic: boom", msg)
+	t.Not.True(rr.Passed())
+}
+
+func (s *RunResults) Pass_sub_tests_whose_sub_tests_passed(t *T) {
+	rr, err := testjson.Unmarshal(events(
+		[3]string{testjson.Run, "TestA", ""},
+		[3]string{testjson.Run, "TestA/sub", ""},
+		[3]string{testjson.Run, "TestA/sub/sub", ""},
+		[3]string{testjson.Pass, "TestA/sub/sub", ""},
+		[3]string{testjson.Pass, "TestA", ""},
+	))
+	t.FatalOn(err)
+
+	t.True(rr.Of("TestA").Of("sub").Passed)
+	t.Eq(1, rr.Of("TestA").Len())
+}
+
+func (s *RunResults) Fail_to_unmarshal_non_json_output(t *T) {
+	_, err := testjson.Unmarshal([]byte("# fx\n./fx.go:3:1: syntax error"))
+	t.ErrMatched(err, "stdout not parsable")
+}
+
+func TestRunResults(t *testing.T) {
+	t.Parallel()
+	Run(&RunResults{}, t)
+}
+
+type Decoding struct{ Suite }
+
+func (s *Decoding) SetUp(t *T) { t.Parallel() }
+
+func (s *Decoding) Reports_events_as_they_are_read(t *T) {
+	r, w := io.Pipe()
+	d := testjson.NewDecoder(r)
+	go func() {
+		w.Write(events([3]string{testjson.Run, "TestA", ""}))
+		w.Write([]byte("\n"))
+	}()
+
+	e, err := d.Next()
+	t.FatalOn(err)
+	t.Eq(testjson.Run, e.Action)
+	t.Eq("TestA", e.Test)
+	t.Not.True(d.Results().Of("TestA").Passed)
+
+	go func() {
+		w.Write(events([3]string{testjson.Pass, "TestA", ""}))
+		w.Close()
+	}()
+	e, err = d.Next()
+	t.FatalOn(err)
+	t.Eq(testjson.Pass, e.Action)
+	t.True(d.Results().Of("TestA").Passed)
+	_, err = d.Next()
+	t.ErrIs(err, io.EOF)
+}
+
+func (s *Decoding) Fails_on_a_non_json_line(t *T) {
+	d := testjson.NewDecoder(strings.NewReader("\n\nno json\n"))
+
+	_, err := d.Next()
+
+	t.ErrMatched(err, "testjson: decode: .*no json")
+}
+
+func (s *Decoding) Keeps_the_order_of_started_tests(t *T) {
+	rr, err := testjson.Decode(bytes.NewReader(events(
+		[3]string{testjson.Run, "TestB", ""},
+		[3]string{testjson.Run, "TestA", ""},
+		[3]string{testjson.Run, "TestC", ""},
+		[3]string{testjson.Pass, "", ""},
+	)))
+	t.FatalOn(err)
+
+	nn := []string{}
+	rr.For(func(r *testjson.TestResult) { nn = append(nn, r.Name) })
+	t.Eq([]string{"TestB", "TestA", "TestC"}, nn)
+	t.Eq(3, rr.Len())
+}
+
+func (s *Decoding) Keys_results_by_package_and_test(t *T) {
+	stream := strings.ReplaceAll(string(events(
+		[3]string{testjson.Run, "TestA", ""},
+		[3]string{testjson.Pass, "TestA", ""},
+	)), `"fx"`, `"a"`) + "\n" + strings.ReplaceAll(string(events(
+		[3]string{testjson.Run, "TestA", ""},
+		[3]string{testjson.Output, "TestA", "    b_test.go:5: failed\n"},
+		[3]string{testjson.Fail, "TestA", ""},
+	)), `"fx"`, `"b"`)
+
+	rr, err := testjson.Decode(strings.NewReader(stream))
+	t.FatalOn(err)
+
+	t.Eq([]string{"a", "b"}, rr.Packages())
+	t.Eq(2, rr.Len())
+	t.Not.True(rr.Passed())
+	t.True(rr.Package("a").Of("TestA").Passed)
+	t.Eq(0, len(rr.Package("a").Of("TestA").Output))
+	t.Not.True(rr.Package("b").Of("TestA").Passed)
+	t.Eq([]string{"b_test.go:5: failed"},
+		rr.Package("b").Of("TestA").Output)
+	t.True(rr.Package("c") == nil)
+}
+
+func (s *Decoding) Humanizes_test_names(t *T) {
+	t.Eq("human readable", testjson.HumanReadable("TestHumanReadable"))
+	t.Eq("doesn't change its input",
+		testjson.HumanReadable("Doesnt_change_its_input"))
+}
+
+func TestDecoding(t *testing.T) {
+	t.Parallel()
+	Run(&Decoding{}, t)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+/*
+Package testjson decodes the output of "go test -json" into a tree of
+test results, i.e. go Test* functions and their sub-tests, which also
+reports panics, data races, flaky gounit suite-tests and the logs of
+gounit suites' Init and Finalize methods.  The results of a test run of
+several packages are kept apart by the packages' import paths, see
+[Results.Package].  The output of a finished test run may be decoded at
+once
+
+	rr, err := testjson.Decode(stdout)
+	if err != nil {
+	    panic(err)
+	}
+	if msg, ok := rr.HasPanic(); ok {
+	    fmt.Println(msg)
+	}
+	rr.For(func(r *testjson.TestResult) {
+	    fmt.Printf("%s: passed: %v\n", r.Name, r.Passed)
+	})
+
+or a running test run's output may be decoded event by event
+
+	d := testjson.NewDecoder(stdoutPipe)
+	for {
+	    e, err := d.Next()
+	    if err != nil {
+	        break // io.EOF if the stream has ended
+	    }
+	    if e.Action == testjson.Fail {
+	        fmt.Printf("%s failed\n", e.Test)
+	    }
+	}
+	rr := d.Results()
+*/
+package testjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Actions of go test events.
+const (
+	Run    = "run"    // the test has started running
+	Pause  = "pause"  // the test has been paused
+	Cont   = "cont"   // the test has continued running
+	Pass   = "pass"   // the test passed
+	Bench  = "bench"  // benchmark printed log output but did not fail
+	Fail   = "fail"   // the test or benchmark failed
+	Output = "output" // the test printed output
+	Skip   = "skip"   // test was skipped or package contained no tests
+)
+
+// Prefixes of gounit's logging-messages which are interpreted by the
+// decoding of test output.
+const (
+
+	// InitPrefix prefixes logging-messages of a suite's Init-method.
+	InitPrefix = "__init__"
+
+	// FinalPrefix prefixes logging-messages of a suite's
+	// Finalize-method.
+	FinalPrefix = "__final__"
+
+	// FlakyPrefix prefixes the logging-message of a suite-test which
+	// failed at least once before it passed.
+	FlakyPrefix = "__flaky__"
+)
+
+// Event is a go test event as documented by "go doc test2json".
+type Event struct {
+	Time    time.Time // encodes as an RFC3339-format string
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64 // seconds
+	Output  string
+}
+
+// A Decoder reads go test events from a stream and adds them to its
+// results as they are read.
+type Decoder struct {
+	scanner *bufio.Scanner
+	rr      *Results
+	done    bool
+}
+
+// NewDecoder returns a new decoder reading go test events from given
+// reader.
+func NewDecoder(r io.Reader) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	return &Decoder{scanner: scanner, rr: NewResults()}
+}
+
+// Next reads the next event from given decoder d's stream, adds it to
+// d's results and returns it.  Next returns io.EOF once the stream has
+// ended and an error if the stream can't be read or an event can't be
+// unmarshaled.  Empty lines are skipped.
+func (d *Decoder) Next() (*Event, error) {
+	for d.scanner.Scan() {
+		raw := bytes.TrimSpace(d.scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		e := &Event{}
+		if err := json.Unmarshal(raw, e); err != nil {
+			return nil, fmt.Errorf("testjson: decode: %w: %s", err, raw)
+		}
+		d.rr.Add(e)
+		return e, nil
+	}
+	if !d.done {
+		d.done = true
+		d.rr.passSubSubs()
+	}
+	if err := d.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("testjson: decode: %w", err)
+	}
+	return nil, io.EOF
+}
+
+// Results returns the results of the events read so far by given
+// decoder d.
+func (d *Decoder) Results() *Results { return d.rr }
+
+// Decode reads all events from given reader and returns their results.
+func Decode(r io.Reader) (*Results, error) {
+	d := NewDecoder(r)
+	for {
+		_, err := d.Next()
+		if err == io.EOF {
+			return d.Results(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// jsonProperties must be all present in a provided stdout in order to
+// unmarshal to events.
+var jsonProperties = [][]byte{
+	[]byte("Time"), []byte("Action"), []byte("Package"), []byte("Test"),
+	[]byte("Output"), []byte("Elapsed")}
+
+// Unmarshal decodes given output of a finished "go test -json"
+// execution.  Unmarshal fails if the output is not made of go test
+// events, e.g. if the tested package doesn't compile.
+func Unmarshal(stdout []byte) (*Results, error) {
+	for _, p := range jsonProperties {
+		if !bytes.Contains(stdout, p) {
+			return nil, fmt.Errorf("unmarshal test-run: "+
+				"stdout not parsable:\n%s", string(stdout))
+		}
+	}
+	return Decode(bytes.NewReader(stdout))
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	fp "path/filepath"
+	"sort"
+	"strings"
+)
+
+// DiffOption configures the comparison of two directories by
+// [Dir.Diff].
+type DiffOption func(*diffOptions)
+
+type diffOptions struct {
+	globs       []string
+	lineEndings bool
+	perms       bool
+	modTimes    bool
+}
+
+// IgnoreGlobs makes [Dir.Diff] ignore files and directories whose slash
+// separated relative path or whose name matches one of given patterns
+// (see [path.Match]).  An ignored directory's content is ignored too.
+func IgnoreGlobs(patterns ...string) DiffOption {
+	return func(o *diffOptions) { o.globs = append(o.globs, patterns...) }
+}
+
+// IgnoreLineEndings makes [Dir.Diff] consider "\r\n" and "\n" line
+// endings as equal.
+func IgnoreLineEndings() DiffOption {
+	return func(o *diffOptions) { o.lineEndings = true }
+}
+
+// IgnorePermissions makes [Dir.Diff] only compare the types of files
+// and directories but not their permissions.
+func IgnorePermissions() DiffOption {
+	return func(o *diffOptions) { o.perms = true }
+}
+
+// ModTimes makes [Dir.Diff] also compare the modification times of
+// files which differ for most copies and are ignored by default.
+func ModTimes() DiffOption {
+	return func(o *diffOptions) { o.modTimes = true }
+}
+
+func (o *diffOptions) ignores(rel string) bool {
+	for _, g := range o.globs {
+		if ok, _ := path.Match(g, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(g, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DirDiff reports the differences of a directory compared to an other
+// directory (see [Dir.Diff]).  All paths are slash separated and
+// relative to the compared directories.
+type DirDiff struct {
+
+	// Missing lists the files and directories of the other directory
+	// which are missing.
+	Missing []string
+
+	// Extra lists the files and directories which the other directory
+	// doesn't have.
+	Extra []string
+
+	// Content lists the files with a different content along with a
+	// unified line diff whereas "-" lines are from the other
+	// directory's file.
+	Content []FileDiff
+
+	// Mode lists the files and directories with a different mode.
+	Mode []FileDiff
+
+	// ModTime lists the files with a different modification time iff
+	// the [ModTimes] option was given.
+	ModTime []FileDiff
+}
+
+// FileDiff reports how a file differs from its counterpart.
+type FileDiff struct {
+	Name string
+	Diff string
+}
+
+// Empty returns true iff no differences were found.
+func (d DirDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 &&
+		len(d.Content) == 0 && len(d.Mode) == 0 && len(d.ModTime) == 0
+}
+
+// String reports the found differences grouped by their kind; an empty
+// string is returned iff there are none.
+func (d DirDiff) String() string {
+	b := &strings.Builder{}
+	names := func(label string, nn []string) {
+		if len(nn) == 0 {
+			return
+		}
+		fmt.Fprintf(b, "%s:\n", label)
+		for _, n := range nn {
+			fmt.Fprintf(b, "    %s\n", n)
+		}
+	}
+	diffs := func(label string, ff []FileDiff) {
+		if len(ff) == 0 {
+			return
+		}
+		fmt.Fprintf(b, "%s:\n", label)
+		for _, f := range ff {
+			if !strings.Contains(f.Diff, "\n") {
+				fmt.Fprintf(b, "    %s: %s\n", f.Name, f.Diff)
+				continue
+			}
+			fmt.Fprintf(b, "    %s:\n", f.Name)
+			for _, l := range strings.Split(
+				strings.TrimRight(f.Diff, "\n"), "\n") {
+				fmt.Fprintf(b, "        %s\n", l)
+			}
+		}
+	}
+	names("missing", d.Missing)
+	names("extra", d.Extra)
+	diffs("content", d.Content)
+	diffs("mode", d.Mode)
+	diffs("mod-time", d.ModTime)
+	return b.String()
+}
+
+// Diff compares given directory d's content with the content of given
+// other directory and reports files and directories which are missing
+// in d, which d has in addition, files whose content differs and files
+// or directories whose mode differs.  Given options allow to ignore
+// files, line endings or permissions and to also compare modification
+// times.  Diff fatales associated test if any of the executed file
+// system operations fails.
+func (d *Dir) Diff(other Pather, oo ...DiffOption) DirDiff {
+	d.t.GoT().Helper()
+	opt := &diffOptions{}
+	for _, o := range oo {
+		o(opt)
+	}
+	got, exp := d.diffInfos(d.path, opt), d.diffInfos(other.Path(), opt)
+	diff := DirDiff{}
+	for rel := range exp {
+		if _, ok := got[rel]; !ok {
+			diff.Missing = append(diff.Missing, rel)
+		}
+	}
+	for rel, g := range got {
+		e, ok := exp[rel]
+		if !ok {
+			diff.Extra = append(diff.Extra, rel)
+			continue
+		}
+		if md := modeDiff(e.Mode(), g.Mode(), opt); md != "" {
+			diff.Mode = append(diff.Mode, FileDiff{Name: rel, Diff: md})
+		}
+		if !e.Mode().IsRegular() || !g.Mode().IsRegular() {
+			continue
+		}
+		if cd := d.contentDiff(other.Path(), rel, opt); cd != "" {
+			diff.Content = append(diff.Content,
+				FileDiff{Name: rel, Diff: cd})
+		}
+		if opt.modTimes && !e.ModTime().Equal(g.ModTime()) {
+			diff.ModTime = append(diff.ModTime, FileDiff{
+				Name: rel, Diff: fmt.Sprintf("%s != %s",
+					e.ModTime().Format("2006-01-02 15:04:05.000000000"),
+					g.ModTime().Format("2006-01-02 15:04:05.000000000")),
+			})
+		}
+	}
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Extra)
+	for _, ff := range [][]FileDiff{diff.Content, diff.Mode, diff.ModTime} {
+		sort.Slice(ff, func(i, j int) bool { return ff[i].Name < ff[j].Name })
+	}
+	return diff
+}
+
+// diffInfos returns the file infos of given root's descendants which
+// are not ignored by given options by their slash separated relative
+// paths.
+func (d *Dir) diffInfos(
+	root string, opt *diffOptions,
+) map[string]fs.FileInfo {
+	ii := map[string]fs.FileInfo{}
+	err := d.fs().Walk(root, func(
+		path string, info fs.FileInfo, err error,
+	) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := fp.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = fp.ToSlash(rel)
+		if opt.ignores(rel) {
+			if info.IsDir() {
+				return fp.SkipDir
+			}
+			return nil
+		}
+		ii[rel] = info
+		return nil
+	})
+	if err != nil {
+		d.t.Fatalf("gounit: fs: dir: diff: %v", err)
+	}
+	return ii
+}
+
+func modeDiff(exp, got fs.FileMode, opt *diffOptions) string {
+	if opt.perms {
+		exp, got = exp.Type(), got.Type()
+	}
+	if exp == got {
+		return ""
+	}
+	return fmt.Sprintf("%v != %v", exp, got)
+}
+
+// contentDiff returns a unified line diff of given relative file in
+// given other directory and in d; an empty string if their content is
+// equal.
+func (d *Dir) contentDiff(other, rel string, opt *diffOptions) string {
+	exp, err := d.fs().ReadFile(fp.Join(other, fp.FromSlash(rel)))
+	if err != nil {
+		d.t.Fatalf("gounit: fs: dir: diff: %v", err)
+	}
+	got, err := d.fs().ReadFile(fp.Join(d.path, fp.FromSlash(rel)))
+	if err != nil {
+		d.t.Fatalf("gounit: fs: dir: diff: %v", err)
+	}
+	if opt.lineEndings {
+		exp = bytes.ReplaceAll(exp, []byte("\r\n"), []byte("\n"))
+		got = bytes.ReplaceAll(got, []byte("\r\n"), []byte("\n"))
+	}
+	return lineDiff(string(exp), string(got))
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"os"
+	fp "path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
+)
+
+type DirDiffing struct{ Suite }
+
+func (s *DirDiffing) SetUp(t *T) { t.Parallel() }
+
+const fxDiffTxtar = `
+-- a.txt --
+a
+b
+-- dir/b.txt --
+b
+-- dir/c.txt --
+c
+`
+
+func fxDiffDirs(t *T) (got, exp *tfs.Dir) {
+	got, exp = t.FS().Tmp(), t.FS().Tmp()
+	got.FromTxtar([]byte(fxDiffTxtar))
+	exp.FromTxtar([]byte(fxDiffTxtar))
+	return got, exp
+}
+
+func (s *DirDiffing) Reports_nothing_for_equal_directories(t *T) {
+	got, exp := fxDiffDirs(t)
+
+	diff := got.Diff(exp)
+
+	t.True(diff.Empty())
+	t.Eq("", diff.String())
+}
+
+func (s *DirDiffing) Reports_missing_and_extra_files(t *T) {
+	got, exp := fxDiffDirs(t)
+	got.Rm(fp.Join("dir", "c.txt"))
+	got.MkFile("extra.txt", []byte("extra"))
+
+	diff := got.Diff(exp)
+
+	t.Eq([]string{"dir/c.txt"}, diff.Missing)
+	t.Eq([]string{"extra.txt"}, diff.Extra)
+	t.StarMatched(diff.String(),
+		"missing:", "dir/c.txt", "extra:", "extra.txt")
+}
+
+func (s *DirDiffing) Reports_content_differences_with_line_diff(t *T) {
+	got, exp := fxDiffDirs(t)
+	got.WriteContent("a.txt", []byte("a\nc\n"))
+
+	diff := got.Diff(exp)
+
+	t.FatalIfNot(t.Eq(1, len(diff.Content)))
+	t.Eq("a.txt", diff.Content[0].Name)
+	t.Eq("@@ -1,2 +1,2 @@\n a\n-b\n+c\n", diff.Content[0].Diff)
+	t.StarMatched(diff.String(), "content:", "a.txt:")
+}
+
+func (s *DirDiffing) Reports_separated_changes_in_separate_hunks(t *T) {
+	got, exp := t.FS().Tmp(), t.FS().Tmp()
+	exp.MkFile("a.txt", []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10"))
+	got.MkFile("a.txt", []byte("0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"))
+
+	diff := got.Diff(exp)
+
+	t.FatalIfNot(t.Eq(1, len(diff.Content)))
+	t.Eq("@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n"+
+		"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n"+
+		"\\ No newline at end of file\n+10\n", diff.Content[0].Diff)
+}
+
+func (s *DirDiffing) Reports_mode_differences(t *T) {
+	got, exp := fxDiffDirs(t)
+	t.FatalOn(os.Chmod(fp.Join(got.Path(), "a.txt"), 0755))
+
+	diff := got.Diff(exp)
+
+	t.FatalIfNot(t.Eq(1, len(diff.Mode)))
+	t.Eq(tfs.FileDiff{Name: "a.txt", Diff: "-rw-r--r-- != -rwxr-xr-x"},
+		diff.Mode[0])
+	t.True(got.Diff(exp, tfs.IgnorePermissions()).Empty())
+}
+
+func (s *DirDiffing) Reports_mod_time_differences_on_request(t *T) {
+	got, exp := fxDiffDirs(t)
+	past := time.Now().Add(-time.Hour)
+	t.FatalOn(os.Chtimes(fp.Join(got.Path(), "a.txt"), past, past))
+
+	t.True(got.Diff(exp).Empty())
+	diff := got.Diff(exp, tfs.ModTimes())
+	t.FatalIfNot(t.True(len(diff.ModTime) > 0))
+	t.Eq("a.txt", diff.ModTime[0].Name)
+}
+
+func (s *DirDiffing) Ignores_given_globs(t *T) {
+	got, exp := fxDiffDirs(t)
+	got.MkFile("go.sum", []byte("sum"))
+	got.Rm("dir")
+
+	t.True(got.Diff(exp, tfs.IgnoreGlobs("go.sum", "dir")).Empty())
+	t.Not.True(got.Diff(exp, tfs.IgnoreGlobs("dir/*")).Empty())
+}
+
+func (s *DirDiffing) Ignores_line_endings_on_request(t *T) {
+	got, exp := fxDiffDirs(t)
+	got.WriteContent("a.txt", []byte("a\r\nb\r\n"))
+
+	t.Not.True(got.Diff(exp).Empty())
+	t.True(got.Diff(exp, tfs.IgnoreLineEndings()).Empty())
+}
+
+func TestDirDiffing(t *testing.T) {
+	t.Parallel()
+	Run(&DirDiffing{}, t)
+}
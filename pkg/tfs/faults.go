@@ -0,0 +1,256 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	fp "path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Op identifies a file system operation of [FS] and [Dir] instances
+// which may be faulted by [FS.WithFaults].
+type Op string
+
+const (
+	OpStat      Op = "Stat"
+	OpGetwd     Op = "Getwd"
+	OpChdir     Op = "Chdir"
+	OpMkdir     Op = "Mkdir"
+	OpMkdirAll  Op = "MkdirAll"
+	OpRemove    Op = "Remove"
+	OpRemoveAll Op = "RemoveAll"
+	OpSymlink   Op = "Symlink"
+	OpOpen      Op = "Open"
+	OpCreate    Op = "Create"
+	OpReadDir   Op = "ReadDir"
+	OpReadFile  Op = "ReadFile"
+	OpWriteFile Op = "WriteFile"
+	OpChmod     Op = "Chmod"
+	OpChtimes   Op = "Chtimes"
+	OpWalk      Op = "Walk"
+)
+
+// ErrFault is returned by a faulted operation whose [Fault] has no
+// error set.
+var ErrFault = errors.New("gounit: fs: injected fault")
+
+// A Fault describes how an operation on matching paths misbehaves (see
+// [FS.WithFaults]):
+//
+//	Fault{Op: OpWriteFile, Glob: "*.go", Err: os.ErrPermission}
+//
+// fails writing go files with given error;
+//
+//	Fault{Op: OpMkdir, Succeed: 2}
+//
+// lets the third and all following directory creations fail with
+// [ErrFault];
+//
+//	Fault{Op: OpReadFile, Delay: 100*time.Millisecond}
+//
+// delays each file read by 100ms.
+type Fault struct {
+
+	// Op is the faulted operation.
+	Op Op
+
+	// Glob is matched against the operation's path (see
+	// [filepath.Match]).  A relative glob is matched against as many
+	// trailing path elements as it has, e.g. "*.go" against the base
+	// name or "pkg/*.go" against the base name and its directory.  An
+	// empty glob matches any path.  The path of Symlink is its link
+	// name while Getwd has no path.
+	Glob string
+
+	// Err is the error a faulted operation fails with.  Err defaults
+	// to [ErrFault] unless the fault is a Delay only.
+	Err error
+
+	// Succeed is the number of matching operations which succeed
+	// before the faulted operation starts failing.
+	Succeed int
+
+	// Delay delays each matching operation by given duration.
+	Delay time.Duration
+}
+
+// WithFaults makes given operations of given file system and all its
+// directories misbehave according to given faults until the test
+// associated with fs ends (see [Fault]).  The first matching fault of
+// an operation determines its behavior.  E.g.:
+//
+//	fs := t.FS().WithFaults(tfs.Fault{
+//	    Op: tfs.OpWriteFile, Glob: "go.sum", Err: os.ErrPermission,
+//	})
+//	fs.Tmp().MkTidy() // fatales since go.sum can't be written
+//
+// Note since failing fs-operations fatal the associated test it is
+// usually necessary to mock its canceler to continue a test.  Note
+// also that operations running concurrently to WithFaults or to the
+// end of the test may or may not be faulted.
+func (fs *FS) WithFaults(ff ...Fault) *FS {
+	fs.t.GoT().Helper()
+	fs.toolsMutex.Lock()
+	defer fs.toolsMutex.Unlock()
+	tools := fs.tools
+	fs.tools = newFaultyTools(tools, ff)
+	fs.t.GoT().Cleanup(func() {
+		fs.toolsMutex.Lock()
+		defer fs.toolsMutex.Unlock()
+		fs.tools = tools
+	})
+	return fs
+}
+
+// faults evaluates injected faults for an operation on a path.
+type faults struct {
+	mutex sync.Mutex
+	ff    []Fault
+	calls []int
+}
+
+// inject returns the error given operation on given path fails with
+// after the fault's delay; nil if it doesn't fail.
+func (ff *faults) inject(op Op, path string) error {
+	ff.mutex.Lock()
+	for i, f := range ff.ff {
+		if f.Op != op || !matches(f.Glob, path) {
+			continue
+		}
+		ff.calls[i]++
+		calls := ff.calls[i]
+		ff.mutex.Unlock()
+		if f.Delay > 0 {
+			time.Sleep(f.Delay)
+		}
+		if calls <= f.Succeed {
+			return nil
+		}
+		if f.Err == nil && f.Delay > 0 && f.Succeed == 0 {
+			return nil
+		}
+		if f.Err == nil {
+			return ErrFault
+		}
+		return f.Err
+	}
+	ff.mutex.Unlock()
+	return nil
+}
+
+func matches(glob, path string) bool {
+	if glob == "" {
+		return true
+	}
+	if !fp.IsAbs(glob) {
+		n := strings.Count(glob, string(fp.Separator)) + 1
+		ss := strings.Split(path, string(fp.Separator))
+		if len(ss) > n {
+			path = fp.Join(ss[len(ss)-n:]...)
+		}
+	}
+	ok, _ := fp.Match(glob, path)
+	return ok
+}
+
+// newFaultyTools wraps given file system tools' path based operations
+// and Getwd with given faults.
+func newFaultyTools(t *fsTools, ff []Fault) *fsTools {
+	f := &faults{ff: ff, calls: make([]int, len(ff))}
+	faulty := t.copy()
+	faulty.Stat = func(p string) (fs.FileInfo, error) {
+		if err := f.inject(OpStat, p); err != nil {
+			return nil, err
+		}
+		return t.Stat(p)
+	}
+	faulty.Getwd = func() (string, error) {
+		if err := f.inject(OpGetwd, ""); err != nil {
+			return "", err
+		}
+		return t.Getwd()
+	}
+	faulty.Chdir = pathFault(f, OpChdir, t.Chdir)
+	faulty.Mkdir = modeFault(f, OpMkdir, t.Mkdir)
+	faulty.MkdirAll = modeFault(f, OpMkdirAll, t.MkdirAll)
+	faulty.Remove = pathFault(f, OpRemove, t.Remove)
+	faulty.RemoveAll = pathFault(f, OpRemoveAll, t.RemoveAll)
+	faulty.Symlink = func(old, new string) error {
+		if err := f.inject(OpSymlink, new); err != nil {
+			return err
+		}
+		return t.Symlink(old, new)
+	}
+	faulty.Open = fileFault(f, OpOpen, t.Open)
+	faulty.Create = fileFault(f, OpCreate, t.Create)
+	faulty.ReadDir = func(p string) ([]fs.DirEntry, error) {
+		if err := f.inject(OpReadDir, p); err != nil {
+			return nil, err
+		}
+		return t.ReadDir(p)
+	}
+	faulty.ReadFile = func(p string) ([]byte, error) {
+		if err := f.inject(OpReadFile, p); err != nil {
+			return nil, err
+		}
+		return t.ReadFile(p)
+	}
+	faulty.WriteFile = func(p string, bb []byte, m fs.FileMode) error {
+		if err := f.inject(OpWriteFile, p); err != nil {
+			return err
+		}
+		return t.WriteFile(p, bb, m)
+	}
+	faulty.Chmod = modeFault(f, OpChmod, t.Chmod)
+	faulty.Chtimes = func(p string, atime, mtime time.Time) error {
+		if err := f.inject(OpChtimes, p); err != nil {
+			return err
+		}
+		return t.Chtimes(p, atime, mtime)
+	}
+	faulty.Walk = func(root string, fn fp.WalkFunc) error {
+		if err := f.inject(OpWalk, root); err != nil {
+			return err
+		}
+		return t.Walk(root, fn)
+	}
+	return faulty
+}
+
+func pathFault(f *faults, op Op, do func(string) error) func(string) error {
+	return func(p string) error {
+		if err := f.inject(op, p); err != nil {
+			return err
+		}
+		return do(p)
+	}
+}
+
+func modeFault(
+	f *faults, op Op, do func(string, fs.FileMode) error,
+) func(string, fs.FileMode) error {
+	return func(p string, m fs.FileMode) error {
+		if err := f.inject(op, p); err != nil {
+			return err
+		}
+		return do(p, m)
+	}
+}
+
+func fileFault(
+	f *faults, op Op, do func(string) (*os.File, error),
+) func(string) (*os.File, error) {
+	return func(p string) (*os.File, error) {
+		if err := f.inject(op, p); err != nil {
+			return nil, err
+		}
+		return do(p)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"fmt"
+	"os"
+	fp "path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
+)
+
+type Faults struct{ Suite }
+
+func (s *Faults) SetUp(t *T) { t.Parallel() }
+
+// fxFatal mocks given test's logger and canceler and returns a pointer
+// to the log of the last cancellation.
+func fxFatal(t *T) *string {
+	log, last := "", ""
+	t.Mock().Logger(func(i ...interface{}) { last = fmt.Sprint(i...) })
+	t.Mock().Canceler(func() { log = last })
+	return &log
+}
+
+func (s *Faults) Fail_matching_operation_with_given_error(t *T) {
+	td := t.FS().WithFaults(tfs.Fault{
+		Op: tfs.OpWriteFile, Glob: "*.go", Err: os.ErrPermission,
+	}).Tmp()
+	fatal := fxFatal(t)
+
+	td.MkFile("a.txt", []byte("a"))
+	t.Eq("", *fatal)
+	td.MkFile("a.go", []byte("package a"))
+
+	t.Contains(*fatal, os.ErrPermission.Error())
+}
+
+func (s *Faults) Fail_after_given_number_of_successes(t *T) {
+	td := t.FS().WithFaults(tfs.Fault{Op: tfs.OpMkdirAll, Succeed: 2}).
+		Tmp()
+	fatal := fxFatal(t)
+
+	td.Mk("a")
+	td.Mk("b")
+	t.Eq("", *fatal)
+	td.Mk("c")
+
+	t.Contains(*fatal, tfs.ErrFault.Error())
+}
+
+func (s *Faults) Fail_touching_a_file(t *T) {
+	td := t.FS().WithFaults(tfs.Fault{
+		Op: tfs.OpChtimes, Glob: "b.txt", Err: os.ErrPermission,
+	}).Tmp()
+	td.MkFile("a.txt", []byte("a"))
+	td.MkFile("b.txt", []byte("b"))
+	fatal := fxFatal(t)
+
+	td.Touch("a.txt")
+	t.Eq("", *fatal)
+	td.Touch("b.txt")
+
+	t.Contains(*fatal, "touch: b.txt: "+os.ErrPermission.Error())
+}
+
+func (s *Faults) Delay_matching_operations(t *T) {
+	td := t.FS().WithFaults(tfs.Fault{
+		Op: tfs.OpReadFile, Glob: fp.Join("*", "slow.txt"),
+		Delay: 20 * time.Millisecond,
+	}).Tmp()
+	td.MkFile("slow.txt", []byte("slow"))
+	start := time.Now()
+
+	t.Eq("slow", string(td.FileContent("slow.txt")))
+
+	t.True(time.Since(start) >= 20*time.Millisecond)
+}
+
+func (s *Faults) Are_undone_at_the_end_of_the_test(t *T) {
+	var fs *tfs.FS
+	t.GoT().Run("faulted", func(_t *testing.T) {
+		fs = NewT(_t).FS().WithFaults(tfs.Fault{Op: tfs.OpWriteFile})
+	})
+	td := fs.Tmp()
+
+	td.MkFile("a.txt", []byte("a"))
+
+	t.Eq("a", string(td.FileContent("a.txt")))
+}
+
+func (s *Faults) May_be_injected_while_directories_are_in_use(t *T) {
+	fs := t.FS()
+	td, done := fs.Tmp(), make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			td.MkFile(fmt.Sprintf("%d.txt", i), []byte("a"))
+		}
+	}()
+
+	fs.WithFaults(tfs.Fault{Op: tfs.OpReadFile, Glob: "b.txt"})
+	<-done
+
+	t.Eq("a", string(td.FileContent("99.txt")))
+}
+
+func TestFaults(t *testing.T) {
+	t.Parallel()
+	Run(&Faults{}, t)
+}
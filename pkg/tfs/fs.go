@@ -45,6 +45,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -65,9 +66,23 @@ type Tester interface {
 // fatal associated testing instance while failing undo function-calls
 // panic.
 type FS struct {
-	t     Tester
-	td    *Dir
-	tools *fsTools
+	t  Tester
+	td *Dir
+
+	// tools are guarded by toolsMutex since they are replaced by
+	// WithFaults while directories may be in use concurrently.
+	tools      *fsTools
+	toolsMutex sync.RWMutex
+
+	// auto is set by AutoUndo while failed collects the failures of
+	// automatically executed undos.
+	auto   bool
+	mutex  sync.Mutex
+	failed []string
+
+	// proxy is the GOPROXY directory set by WithModuleProxy while
+	// modCache is the test specific module cache used with it.
+	proxy, modCache string
 }
 
 func New(t Tester) *FS {
@@ -75,7 +90,11 @@ func New(t Tester) *FS {
 }
 
 // tls provides the file system tools to created Dir and TmpDir instances.
-func (fs *FS) tls() *fsTools { return fs.tools }
+func (fs *FS) tls() *fsTools {
+	fs.toolsMutex.RLock()
+	defer fs.toolsMutex.RUnlock()
+	return fs.tools
+}
 
 // Data returns the callers testdata directory.  Associated testing
 // instance fatales if the directory doesn't exist and can't be created.
@@ -85,11 +104,11 @@ func (fs *FS) tls() *fsTools { return fs.tools }
 // execution fails.
 func (fs *FS) Data() (_ *Dir, undo func()) {
 	if fs.td != nil {
-		if _, err := os.Stat(fs.td.path); err == nil {
+		if _, err := fs.tls().Stat(fs.td.path); err == nil {
 			return fs.td, nil
 		}
 	}
-	_, f, _, ok := fs.tools.Caller(1)
+	_, f, _, ok := fs.tls().Caller(1)
 	if !ok {
 		fs.t.Fatal("gounit: fs: testdata: can't determine caller")
 	}
@@ -103,30 +122,37 @@ func (fs *FS) Data() (_ *Dir, undo func()) {
 // undo panics if its execution fails.
 func (fs *FS) Dir(path string) (_ *Dir, undo func()) {
 	created := false
-	if _, err := fs.tools.Stat(path); err != nil {
-		if err := fs.tools.MkdirAll(path, 0711); err != nil {
+	if _, err := fs.tls().Stat(path); err != nil {
+		if err := fs.tls().MkdirAll(path, 0711); err != nil {
 			fs.t.Fatal("gounit: fs: testdata: create: %v", err)
 		}
 		created = true
 	}
-	fs.td = &Dir{t: fs.t, path: path, fs: fs.tls}
+	fs.td = &Dir{t: fs.t, path: path, fs: fs.tls, fsys: fs}
 
 	if !created {
 		return fs.td, nil
 	}
 
-	return fs.td, func() {
-		if err := fs.tools.RemoveAll(path); err != nil {
+	return fs.td, fs.autoUndo(func() {
+		if err := fs.tls().RemoveAll(path); err != nil {
 			panic(err)
 		}
-	}
+	})
 }
 
 // Tmp creates a new unique temporary directory bound to associated
 // testing instance.  Associated testing instance fatales if the temp
 // directory creation fails.
 func (fs *FS) Tmp() *Dir {
-	return &Dir{t: fs.t, path: fs.t.GoT().TempDir(), fs: fs.tls}
+	if tools := fs.tls(); tools.mem != nil {
+		path := tools.mem.tmpPath(fs.t.GoT().Name())
+		if err := tools.MkdirAll(path, 0711); err != nil {
+			fs.t.Fatalf("gounit: fs: tmp-dir: create: %v", err)
+		}
+		return &Dir{t: fs.t, path: path, fs: fs.tls, fsys: fs}
+	}
+	return &Dir{t: fs.t, path: fs.t.GoT().TempDir(), fs: fs.tls, fsys: fs}
 }
 
 // Dir provides file system operations inside its path, which is
@@ -142,10 +168,14 @@ func (fs *FS) Tmp() *Dir {
 type Dir struct {
 	t    Tester
 	fs   func() *fsTools
+	fsys *FS
 	path string
 }
 
-// Path returns the directory's path.
+// Path returns the directory's path.  The path of a directory of an
+// in-memory file system (see [NewMem]) is a virtual absolute path,
+// i.e. it is only valid for operations of the directory's FS and
+// doesn't exist on disk.
 func (d *Dir) Path() string { return d.path }
 
 // Child returns a Dir in given directory d with given name.  Child
@@ -159,7 +189,8 @@ func (d *Dir) Child(name string) *Dir {
 	if !stt.IsDir() {
 		d.t.Fatalf("gounit: fs: dir: child: %s: is no directory", name)
 	}
-	return &Dir{t: d.t, fs: d.fs, path: fp.Join(d.path, name)}
+	return &Dir{t: d.t, fs: d.fs, fsys: d.fsys,
+		path: fp.Join(d.path, name)}
 }
 
 type Pather interface{ Path() string }
@@ -193,7 +224,7 @@ func (d *Dir) Copy(toDir Pather) (undo func()) {
 		if !info.Mode().IsRegular() {
 			switch info.Mode().Type() & os.ModeType {
 			case os.ModeSymlink:
-				link, err := os.Readlink(path)
+				link, err := d.readlink(path)
 				if err != nil {
 					return err
 				}
@@ -205,6 +236,14 @@ func (d *Dir) Copy(toDir Pather) (undo func()) {
 			)
 		}
 
+		if d.fs().mem != nil {
+			bb, err := d.fs().ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return d.fs().WriteFile(dstPath, bb, info.Mode())
+		}
+
 		src, err := d.fs().Open(path)
 		if err != nil {
 			return err
@@ -231,12 +270,12 @@ func (d *Dir) Copy(toDir Pather) (undo func()) {
 		d.t.Fatalf("gounit: fs: dir: copy: %v", err)
 	}
 
-	return func() {
+	return d.autoUndo(func() {
 		dir := fp.Join(toDir.Path(), fp.Base(d.path))
 		if err := d.fs().RemoveAll(dir); err != nil {
 			panic(err)
 		}
-	}
+	})
 }
 
 // Eq return true if given dir d's and given path p's last directories
@@ -323,14 +362,14 @@ func (d *Dir) Mk(dir string, path ...string) (_ *Dir, undo func()) {
 	if err := d.fs().MkdirAll(_path, 0711); err != nil {
 		d.t.Fatalf("gounit: fs: dir: create: %v", err)
 	}
-	new := &Dir{t: d.t, path: _path, fs: d.fs}
-	return new, func() {
+	new := &Dir{t: d.t, path: _path, fs: d.fs, fsys: d.fsys}
+	return new, d.autoUndo(func() {
 		new.t = nil
 		new.path = ""
 		if err := d.fs().RemoveAll(fp.Join(d.path, dir)); err != nil {
 			panic(fmt.Sprintf("gounit: fs: dir: reset: %v", err))
 		}
-	}
+	})
 }
 
 // Rm removes in given directory d given relatives directory rel with
@@ -359,11 +398,11 @@ func (d *Dir) MkFile(name string, content []byte) (undo func()) {
 		d.t.Fatalf("gounit: fs: dir: add file: write: %v", err)
 	}
 
-	return func() {
+	return d.autoUndo(func() {
 		if err := d.fs().Remove(fl); err != nil {
 			panic(fmt.Sprintf("fx: add file: reset: %v", err))
 		}
-	}
+	})
 }
 
 // FileCopy copies the content of given file from given directory d to
@@ -381,11 +420,11 @@ func (d *Dir) FileCopy(file string, toDir Pather) (undo func()) {
 	if err != nil {
 		d.t.Fatalf("gounit: fs: dir: copy: write: %s: %v", file, err)
 	}
-	return func() {
+	return d.autoUndo(func() {
 		if err := d.fs().Remove(fp.Join(toDir.Path(), file)); err != nil {
 			panic(err)
 		}
-	}
+	})
 }
 
 // FileContent joins given directory d with given file name relName and returns
@@ -433,18 +472,20 @@ func (d *Dir) FileMod(relName string) time.Time {
 // directory or if its modification time can't be updated.
 func (d *Dir) Touch(relName string) {
 	fileName := fp.Join(d.path, relName)
-	if _, err := os.Stat(fileName); err != nil {
+	if _, err := d.fs().Stat(fileName); err != nil {
 		d.t.Fatalf("gounit: fs: dir: touch: %s: %v", relName, err)
 	}
 	now := time.Now()
-	if err := os.Chtimes(fileName, now, now); err != nil {
+	if err := d.fs().Chtimes(fileName, now, now); err != nil {
 		d.t.Fatalf("gounit: fs: dir: touch: %s: %v", relName, err)
 	}
 }
 
 // MkMod adds to given directory d a go.mod file with given module name.
 // It fatales if subsequent [Dir.MkFile] call fatales.  Returned undo
-// panics if its execution fails.
+// panics if its execution fails.  See [Dir.MkGoVersion],
+// [Dir.MkRequire], [Dir.MkModReplace] and [Dir.MkWork] to complete the
+// module or to compose it with other modules.
 func (d *Dir) MkMod(module string) (undo func()) {
 	d.t.GoT().Helper()
 	return d.MkFile("go.mod", []byte(fmt.Sprintf("module %s", module)))
@@ -453,7 +494,9 @@ func (d *Dir) MkMod(module string) (undo func()) {
 // MkTidy tries to make sure that a temporary go module has all packages
 // references needed by its packages.  Once successfully executed go.mod
 // and go.sum are cached in the users caching path's gounit directory.
-// This cached files are considered stale every 24 hours.
+// This cached files are considered stale every 24 hours.  If d's file
+// system has a module proxy (see [FS.WithModuleProxy]) the cache is
+// bypassed and dependencies are served by the proxy only.
 func (d *Dir) MkTidy() {
 	d.t.GoT().Helper()
 	mdlName := d.moduleName()
@@ -486,8 +529,9 @@ func (d *Dir) HasCachedModSum() bool {
 func (d *Dir) mkGoModSum(module string) {
 	modFl := fp.Join(d.path, "go.mod")
 	sumFl := fp.Join(d.path, "go.sum")
+	proxied := d.fsys != nil && d.fsys.proxy != ""
 	mod, sum, ok := goModSumFromCache(d.fs(), module)
-	if ok {
+	if ok && !proxied {
 		if err := d.fs().WriteFile(modFl, mod, 0644); err != nil {
 			d.t.Fatal("gounit: fs: tmp-dir: tidy: write go.mod: %v",
 				err)
@@ -499,11 +543,14 @@ func (d *Dir) mkGoModSum(module string) {
 		return
 	}
 	cmd := exec.Command("go", "mod", "tidy")
-	cmd.Dir = d.path
+	cmd.Dir, cmd.Env = d.path, d.goEnv()
 	if stdout, err := cmd.CombinedOutput(); err != nil {
 		d.t.Fatalf("gounit: fs: tmp-dir: tidy: go mod tidy: %v:\n%v",
 			err, stdout)
 	}
+	if proxied {
+		return
+	}
 	bbMod, err := d.fs().ReadFile(modFl)
 	if err != nil {
 		return
@@ -632,11 +679,11 @@ func (d *Dir) CWD() (undo func()) {
 		return nil
 	}
 
-	return func() {
+	return d.autoUndo(func() {
 		if err := d.fs().Chdir(wd); err != nil {
 			panic(fmt.Sprintf("gounit: fs: tmp-dir: cwd: reset: %v", err))
 		}
-	}
+	})
 }
 
 // fsTools are the functions for potentially failing file system
@@ -685,6 +732,9 @@ type fsTools struct {
 	// Chmod defaults to and has the semantics of os.Chmod
 	Chmod func(string, fs.FileMode) error
 
+	// Chtimes defaults to and has the semantics of os.Chtimes
+	Chtimes func(string, time.Time, time.Time) error
+
 	// Copy defaults to and has the semantics of io.Copy
 	Copy func(io.Writer, io.Reader) (int64, error)
 
@@ -693,6 +743,9 @@ type fsTools struct {
 
 	// Caller default to and has the semantics of runtime.Caller
 	Caller func(int) (uintptr, string, int, bool)
+
+	// mem is the in-memory file system of tools created by NewMem.
+	mem *memFS
 }
 
 func (t *fsTools) copy() *fsTools {
@@ -711,9 +764,11 @@ func (t *fsTools) copy() *fsTools {
 		ReadFile:  t.ReadFile,
 		WriteFile: t.WriteFile,
 		Chmod:     t.Chmod,
+		Chtimes:   t.Chtimes,
 		Copy:      t.Copy,
 		Walk:      t.Walk,
 		Caller:    t.Caller,
+		mem:       t.mem,
 	}
 }
 
@@ -733,6 +788,7 @@ var defaultFSTools = func() *fsTools {
 		ReadFile:  os.ReadFile,
 		WriteFile: os.WriteFile,
 		Chmod:     os.Chmod,
+		Chtimes:   os.Chtimes,
 		Copy:      io.Copy,
 		Walk:      fp.Walk,
 		Caller:    runtime.Caller,
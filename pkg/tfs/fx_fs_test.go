@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"os"
 	fp "path/filepath"
+	"time"
 )
 
 type FSfx struct {
@@ -108,6 +109,11 @@ func (m *fsTMocker) Chmod(f func(string, fs.FileMode) error) {
 	m.fs.tools.Chmod = f
 }
 
+// Chtimes defaults to and has the semantics of os.Chtimes.
+func (m *fsTMocker) Chtimes(f func(string, time.Time, time.Time) error) {
+	m.fs.tools.Chtimes = f
+}
+
 // Copy defaults to and has the semantics of io.Copy
 func (m *fsTMocker) Copy(f func(io.Writer, io.Reader) (int64, error)) {
 	m.fs.tools.Copy = f
@@ -0,0 +1,142 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/slukits/gounit/pkg/testjson"
+)
+
+// BuildResult reports the outcome of a [Dir.GoBuild] execution.
+type BuildResult struct {
+
+	// Err is the go command's exit error; nil if it succeeded.
+	Err error
+
+	// Output is the go command's combined output.
+	Output string
+
+	// Errors lists the positioned compile errors of Output.
+	Errors []BuildError
+}
+
+// Passed returns true iff the build succeeded.
+func (r *BuildResult) Passed() bool { return r.Err == nil }
+
+// BuildError is a compile error at a position of a source file whose
+// name is reported as given by the go command, i.e. usually relative to
+// the built directory.
+type BuildError struct {
+	File         string
+	Line, Column int
+	Msg          string
+}
+
+var reBuildErr = regexp.MustCompile(`^(\S+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// GoBuild executes "go build" with given arguments in given directory d
+// and reports its outcome.  The environment of the go command is set up
+// according to d's file system, e.g. see [FS.WithModuleProxy].  GoBuild
+// fatales associated test if the go command can't be executed.
+func (d *Dir) GoBuild(args ...string) *BuildResult {
+	d.t.GoT().Helper()
+	out, err := d.goCmd("build", args).CombinedOutput()
+	d.fatalOnExec("build", err)
+	r := &BuildResult{Err: err, Output: string(out)}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		mm := reBuildErr.FindStringSubmatch(scanner.Text())
+		if mm == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(mm[2])
+		column, _ := strconv.Atoi(mm[3])
+		r.Errors = append(r.Errors, BuildError{
+			File: mm[1], Line: line, Column: column, Msg: mm[4]})
+	}
+	return r
+}
+
+// TestRunResult reports the outcome of a [Dir.GoTest] execution.
+type TestRunResult struct {
+
+	// Err is the go command's exit error; nil if all tests passed.
+	Err error
+
+	// Output is the go command's output which is not reported by a
+	// test, e.g. build errors.
+	Output string
+
+	// Results provides the executed tests of each tested package as tree
+	// of go Test* functions and their sub-tests, e.g. to find failed
+	// tests, panics or flaky suite-tests.
+	Results *testjson.Results
+}
+
+// Passed returns true iff the go command succeeded.
+func (r *TestRunResult) Passed() bool { return r.Err == nil }
+
+// GoTest executes "go test -json" with given arguments in given
+// directory d and reports its outcome.  The environment of the go
+// command is set up according to d's file system, e.g. see
+// [FS.WithModuleProxy].  GoTest fatales associated test if the go
+// command can't be executed or its output can't be read.
+func (d *Dir) GoTest(args ...string) *TestRunResult {
+	d.t.GoT().Helper()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := d.goCmd("test", append([]string{"-json"}, args...))
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	err := cmd.Run()
+	d.fatalOnExec("test", err)
+	r := &TestRunResult{Err: err, Results: testjson.NewResults()}
+	output := &strings.Builder{}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		e := &testjson.Event{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil ||
+			e.Action == "" {
+			output.WriteString(scanner.Text() + "\n")
+			continue
+		}
+		r.Results.Add(e)
+		if e.Test == "" && e.Action == "build-output" {
+			output.WriteString(e.Output)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		d.t.Fatalf("gounit: fs: dir: go test: %v", err)
+	}
+	output.Write(stderr.Bytes())
+	r.Output = output.String()
+	return r
+}
+
+func (d *Dir) goCmd(sub string, args []string) *exec.Cmd {
+	cmd := exec.Command("go", append([]string{sub}, args...)...)
+	cmd.Dir, cmd.Env = d.path, d.goEnv()
+	return cmd
+}
+
+// fatalOnExec fatales associated test if given error of a go command
+// isn't an exit error, i.e. the command couldn't be executed.
+func (d *Dir) fatalOnExec(sub string, err error) {
+	d.t.GoT().Helper()
+	if err == nil {
+		return
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		d.t.Fatalf("gounit: fs: dir: go %s: %v", sub, err)
+	}
+}
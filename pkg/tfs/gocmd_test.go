@@ -0,0 +1,127 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
+)
+
+type GoCmd struct{ Suite }
+
+func (s *GoCmd) SetUp(t *T) { t.Parallel() }
+
+func mkPkg(t *T) *tfs.Dir {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/pkg")
+	pkg, _ := mod.Mk("pkg")
+	return pkg
+}
+
+func (s *GoCmd) Build_reports_success(t *T) {
+	pkg := mkPkg(t)
+	pkg.MkPkgFile("pkg", []byte("func F() int { return 42 }\n"))
+
+	r := pkg.GoBuild()
+
+	t.True(r.Passed())
+	t.Eq(0, len(r.Errors))
+}
+
+func (s *GoCmd) Build_reports_positioned_compile_errors(t *T) {
+	pkg := mkPkg(t)
+	pkg.MkPkgFile("pkg", []byte("func F() int { return \"42\" }\n"))
+
+	r := pkg.GoBuild()
+
+	t.Not.True(r.Passed())
+	t.FatalIfNot(t.Eq(1, len(r.Errors)))
+	t.Eq("./pkg.go", r.Errors[0].File)
+	t.Eq(3, r.Errors[0].Line)
+	t.True(r.Errors[0].Column > 0)
+	t.Contains(r.Errors[0].Msg, "42")
+}
+
+const fxGoTest = `
+import "testing"
+
+func TestPass(t *testing.T) { t.Log("pass-log") }
+
+func TestFail(t *testing.T) { t.Error("fail-log") }
+
+func TestSkip(t *testing.T) { t.Skip("skip-log") }
+
+func TestSubs(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {})
+	t.Run("fail", func(t *testing.T) { t.Fatal("sub-fail-log") })
+}
+`
+
+func (s *GoCmd) Test_reports_the_outcome_of_each_test(t *T) {
+	pkg := mkPkg(t)
+	pkg.MkPkgTest("pkg", []byte(fxGoTest))
+
+	r := pkg.GoTest()
+
+	t.Not.True(r.Passed())
+	t.Eq(4, r.Results.Len())
+	t.True(r.Results.Of("TestPass").Passed)
+	t.Eq([]string{"pkg_test.go:5: pass-log"},
+		r.Results.Of("TestPass").Output)
+	t.Not.True(r.Results.Of("TestFail").Passed)
+	t.Contains(r.Results.Of("TestFail").Output[0], "fail-log")
+	t.True(r.Results.Of("TestSkip").Skipped)
+	subs := r.Results.Of("TestSubs")
+	t.Not.True(subs.Passed)
+	t.True(subs.Of("pass").Passed)
+	t.Eq(1, subs.LenFailed())
+	t.True(r.Results.Of("TestMissing") == nil)
+}
+
+func (s *GoCmd) Test_passes_given_arguments(t *T) {
+	pkg := mkPkg(t)
+	pkg.MkPkgTest("pkg", []byte(fxGoTest))
+
+	r := pkg.GoTest("-run", "TestPass")
+
+	t.True(r.Passed())
+	t.Eq(1, r.Results.Len())
+}
+
+func (s *GoCmd) Test_reports_the_tests_of_each_package(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/mod")
+	a, _ := mod.Mk("a")
+	a.MkPkgTest("a", []byte(fxGoTest))
+	b, _ := mod.Mk("b")
+	b.MkPkgTest("b", []byte(
+		"import \"testing\"\n\nfunc TestFail(t *testing.T) {}\n"))
+
+	r := mod.GoTest("./...")
+
+	t.Eq([]string{"example.com/mod/a", "example.com/mod/b"},
+		r.Results.Packages())
+	t.Not.True(r.Results.Package("example.com/mod/a").
+		Of("TestFail").Passed)
+	t.True(r.Results.Package("example.com/mod/b").Of("TestFail").Passed)
+}
+
+func (s *GoCmd) Test_reports_build_failures_in_its_output(t *T) {
+	pkg := mkPkg(t)
+	pkg.MkPkgTest("pkg", []byte("func F() int { return \"42\" }\n"))
+
+	r := pkg.GoTest()
+
+	t.Not.True(r.Passed())
+	t.Eq(0, r.Results.Len())
+	t.Contains(r.Output, "pkg_test.go:3")
+}
+
+func TestGoCmd(t *testing.T) {
+	t.Parallel()
+	Run(&GoCmd{}, t)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"io/fs"
+	"os"
+	fp "path/filepath"
+)
+
+// WritableFS is an io/fs file system which can also be written to.  It
+// is returned by [Dir.FS] whereas names are slash separated and
+// relative to the directory (see [fs.ValidPath]).
+type WritableFS interface {
+	fs.StatFS
+	fs.ReadFileFS
+	fs.ReadDirFS
+
+	// WriteFile has the semantics of os.WriteFile.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+
+	// MkdirAll has the semantics of os.MkdirAll.
+	MkdirAll(name string, perm fs.FileMode) error
+
+	// Remove has the semantics of os.Remove.
+	Remove(name string) error
+}
+
+// FS returns an io/fs view of given directory d which is backed by d's
+// file system, i.e. by the disk or by memory (see [NewMem]).  Hence
+// code under test accepting an [fs.FS] may be run against the files of
+// d.  Note failing operations of the returned file system return their
+// error and don't fatal associated test.
+func (d *Dir) FS() WritableFS { return &dirFS{d: d} }
+
+type dirFS struct{ d *Dir }
+
+// path returns the path of given name in the directory of given
+// file system; given op is reported if name is invalid.
+func (f *dirFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return fp.Join(f.d.path, fp.FromSlash(name)), nil
+}
+
+func (f *dirFS) Open(name string) (fs.File, error) {
+	path, err := f.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if m := f.d.fs().mem; m != nil {
+		return m.open(path)
+	}
+	file, err := f.d.fs().Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *dirFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := f.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.d.fs().Stat(path)
+}
+
+func (f *dirFS) ReadFile(name string) ([]byte, error) {
+	path, err := f.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.d.fs().ReadFile(path)
+}
+
+func (f *dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := f.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.d.fs().ReadDir(path)
+}
+
+func (f *dirFS) WriteFile(
+	name string, data []byte, perm fs.FileMode,
+) error {
+	path, err := f.path("open", name)
+	if err != nil {
+		return err
+	}
+	return f.d.fs().WriteFile(path, data, perm)
+}
+
+func (f *dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	path, err := f.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return f.d.fs().MkdirAll(path, perm)
+}
+
+func (f *dirFS) Remove(name string) error {
+	path, err := f.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return f.d.fs().Remove(path)
+}
+
+// readlink returns the destination of given symlink path of d's file
+// system.
+func (d *Dir) readlink(path string) (string, error) {
+	if m := d.fs().mem; m != nil {
+		return m.readlink(path)
+	}
+	return os.Readlink(path)
+}
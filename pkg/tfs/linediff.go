@@ -0,0 +1,166 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines a hunk of a line diff
+// shows around its changes.
+const diffContext = 3
+
+// edit is an unchanged (' '), removed ('-') or added ('+') line of a
+// line diff.
+type edit struct {
+	kind byte
+	line string
+}
+
+// lineDiff returns a unified diff of given expected and given actual
+// text whereas "-" lines are only found in exp and "+" lines only in
+// got; an empty string if both are equal.
+func lineDiff(exp, got string) string {
+	if exp == got {
+		return ""
+	}
+	return unified(edits(lines(exp), lines(got)), diffContext)
+}
+
+// lines splits given text into its lines keeping their line breaks,
+// i.e. a last line without line break can be told apart.
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	ll := strings.SplitAfter(s, "\n")
+	if ll[len(ll)-1] == "" {
+		ll = ll[:len(ll)-1]
+	}
+	return ll
+}
+
+// edits returns the edits turning given expected lines into given
+// actual lines based on their longest common subsequence.
+func edits(exp, got []string) []edit {
+	pre := 0
+	for pre < len(exp) && pre < len(got) && exp[pre] == got[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(exp)-pre && suf < len(got)-pre &&
+		exp[len(exp)-1-suf] == got[len(got)-1-suf] {
+
+		suf++
+	}
+	e, g := exp[pre:len(exp)-suf], got[pre:len(got)-suf]
+	lcs := make([][]int, len(e)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(g)+1)
+	}
+	for i := len(e) - 1; i >= 0; i-- {
+		for j := len(g) - 1; j >= 0; j-- {
+			switch {
+			case e[i] == g[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	ee := []edit{}
+	for _, l := range exp[:pre] {
+		ee = append(ee, edit{' ', l})
+	}
+	i, j := 0, 0
+	for i < len(e) || j < len(g) {
+		switch {
+		case i < len(e) && j < len(g) && e[i] == g[j]:
+			ee = append(ee, edit{' ', e[i]})
+			i++
+			j++
+		case j < len(g) && (i == len(e) || lcs[i][j+1] > lcs[i+1][j]):
+			ee = append(ee, edit{'+', g[j]})
+			j++
+		default:
+			ee = append(ee, edit{'-', e[i]})
+			i++
+		}
+	}
+	for _, l := range exp[len(exp)-suf:] {
+		ee = append(ee, edit{' ', l})
+	}
+	return ee
+}
+
+// unified renders given edits as hunks with given number of context
+// lines.
+func unified(ee []edit, context int) string {
+	// expNo and gotNo are the numbers of expected and actual lines
+	// preceding an edit.
+	expNo, gotNo := make([]int, len(ee)+1), make([]int, len(ee)+1)
+	for k, e := range ee {
+		expNo[k+1], gotNo[k+1] = expNo[k], gotNo[k]
+		if e.kind != '+' {
+			expNo[k+1]++
+		}
+		if e.kind != '-' {
+			gotNo[k+1]++
+		}
+	}
+	nextChange := func(k int) int {
+		for k < len(ee) && ee[k].kind == ' ' {
+			k++
+		}
+		return k
+	}
+	b := &strings.Builder{}
+	for start := 0; ; {
+		c := nextChange(start)
+		if c == len(ee) {
+			return b.String()
+		}
+		from, end := c-context, c
+		if from < start {
+			from = start
+		}
+		for {
+			for end < len(ee) && ee[end].kind != ' ' {
+				end++
+			}
+			next := nextChange(end)
+			if next == len(ee) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		to := end + context
+		if to > len(ee) {
+			to = len(ee)
+		}
+		fmt.Fprintf(b, "@@ -%s +%s @@\n",
+			hunkRange(expNo[from], expNo[to]-expNo[from]),
+			hunkRange(gotNo[from], gotNo[to]-gotNo[from]))
+		for _, e := range ee[from:to] {
+			fmt.Fprintf(b, "%c%s\n", e.kind, strings.TrimSuffix(e.line, "\n"))
+			if !strings.HasSuffix(e.line, "\n") {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		start = to
+	}
+}
+
+// hunkRange formats the range of a hunk's lines following given number
+// of preceding lines.
+func hunkRange(preceding, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", preceding)
+	}
+	return fmt.Sprintf("%d,%d", preceding+1, n)
+}
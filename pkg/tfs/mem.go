@@ -0,0 +1,485 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	fp "path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrMem is returned by file system operations an in-memory [FS] (see
+// [NewMem]) doesn't support, i.e. changing the working directory or
+// opening an *os.File.
+var ErrMem = errors.New("gounit: fs: not supported in memory")
+
+// NewMem returns an FS-instance whose directories and files only exist
+// in memory while supporting the same [Dir] API as an FS-instance
+// returned by [New].  Since a Dir's [Dir.FS] view provides an io/fs
+// file system code under test accepting an [fs.FS] can be run without
+// touching the disk:
+//
+//	func (s *MySuite) Suite_test(t *gounit.T) {
+//	    td := tfs.NewMem(t).Tmp()
+//	    td.MkFile("config.json", []byte(`{"answer":42}`))
+//	    cfg := LoadConfig(td.FS(), "config.json") // code under test
+//	    // ...
+//	}
+//
+// Note the paths of an in-memory FS are virtual, i.e. they don't exist
+// on disk and are meaningless for os-functions or external processes.
+// Hence [Dir.CWD] and [Dir.MkTidy] fatale associated test.  [Dir.Copy]
+// and [Dir.Eq] work only between directories of the same in-memory FS.
+func NewMem(t Tester) *FS {
+	return &FS{t: t, tools: newMemTools(newMemFS())}
+}
+
+// memFS is a thread-safe in-memory tree of files, directories and
+// symlinks keyed by their absolute clean paths.
+type memFS struct {
+	mutex sync.Mutex
+	nn    map[string]*memNode
+	tmp   int
+}
+
+type memNode struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+	link    string
+}
+
+func newMemFS() *memFS {
+	return &memFS{nn: map[string]*memNode{
+		string(fp.Separator): {mode: fs.ModeDir | 0711,
+			modTime: time.Now()},
+	}}
+}
+
+// newMemTools returns file system tools operating on given in-memory
+// file system.
+func newMemTools(m *memFS) *fsTools {
+	return &fsTools{
+		Stat:      m.stat,
+		Getwd:     func() (string, error) { return "", ErrMem },
+		Chdir:     func(string) error { return ErrMem },
+		Mkdir:     m.mkdir,
+		MkdirAll:  m.mkdirAll,
+		Remove:    m.remove,
+		RemoveAll: m.removeAll,
+		Symlink:   m.symlink,
+		Open:      func(string) (*os.File, error) { return nil, ErrMem },
+		Create:    func(string) (*os.File, error) { return nil, ErrMem },
+		ReadDir:   m.readDir,
+		ReadFile:  m.readFile,
+		WriteFile: m.writeFile,
+		Chmod:     m.chmod,
+		Chtimes:   m.chtimes,
+		Copy:      io.Copy,
+		Walk:      m.walk,
+		Caller:    runtime.Caller,
+		mem:       m,
+	}
+}
+
+// tmpPath returns a new unique temporary directory path for given
+// test name.
+func (m *memFS) tmpPath(test string) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.tmp++
+	return fp.Join(string(fp.Separator), "tmp",
+		strings.ReplaceAll(test, "/", "_"), fmt.Sprintf("%03d", m.tmp))
+}
+
+func memPath(path string) (string, error) {
+	if !fp.IsAbs(path) {
+		return "", ErrMem
+	}
+	return fp.Clean(path), nil
+}
+
+func (m *memFS) node(op, path string) (string, *memNode, error) {
+	p, err := memPath(path)
+	if err != nil {
+		return "", nil, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+	n, ok := m.nn[p]
+	if !ok {
+		return p, nil, &fs.PathError{
+			Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+	return p, n, nil
+}
+
+// parent returns an error iff given path's parent is no directory.
+func (m *memFS) parent(op, p string) error {
+	n, ok := m.nn[fp.Dir(p)]
+	if !ok {
+		return &fs.PathError{Op: op, Path: p, Err: fs.ErrNotExist}
+	}
+	if !n.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: p,
+			Err: errors.New("not a directory")}
+	}
+	return nil
+}
+
+func (m *memFS) stat(path string) (fs.FileInfo, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	p, n, err := m.node("stat", path)
+	if err != nil {
+		return nil, err
+	}
+	return n.info(p), nil
+}
+
+func (m *memFS) mkdir(path string, mode fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.mkdirLocked(path, mode)
+}
+
+func (m *memFS) mkdirLocked(path string, mode fs.FileMode) error {
+	p, _, err := m.node("mkdir", path)
+	if err == nil {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrExist}
+	}
+	if p == "" {
+		return err
+	}
+	if err := m.parent("mkdir", p); err != nil {
+		return err
+	}
+	m.nn[p] = &memNode{mode: fs.ModeDir | mode.Perm(),
+		modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) mkdirAll(path string, mode fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	p, err := memPath(path)
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: err}
+	}
+	dirs := []string{}
+	for d := p; ; d = fp.Dir(d) {
+		if n, ok := m.nn[d]; ok {
+			if !n.mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: d,
+					Err: errors.New("not a directory")}
+			}
+			break
+		}
+		dirs = append(dirs, d)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := m.mkdirLocked(dirs[i], mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memFS) remove(path string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	p, n, err := m.node("remove", path)
+	if err != nil {
+		return err
+	}
+	if n.mode.IsDir() && len(m.children(p)) > 0 {
+		return &fs.PathError{Op: "remove", Path: path,
+			Err: errors.New("directory not empty")}
+	}
+	delete(m.nn, p)
+	return nil
+}
+
+func (m *memFS) removeAll(path string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	p, err := memPath(path)
+	if err != nil {
+		return &fs.PathError{Op: "removeall", Path: path, Err: err}
+	}
+	prefix := strings.TrimSuffix(p, string(fp.Separator)) +
+		string(fp.Separator)
+	for k := range m.nn {
+		if k == p || strings.HasPrefix(k, prefix) {
+			delete(m.nn, k)
+		}
+	}
+	return nil
+}
+
+func (m *memFS) symlink(old, new string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	p, _, err := m.node("symlink", new)
+	if err == nil {
+		return &fs.PathError{Op: "symlink", Path: new, Err: fs.ErrExist}
+	}
+	if p == "" {
+		return err
+	}
+	if err := m.parent("symlink", p); err != nil {
+		return err
+	}
+	m.nn[p] = &memNode{mode: fs.ModeSymlink | 0777, link: old,
+		modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) readlink(path string) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, n, err := m.node("readlink", path)
+	if err != nil {
+		return "", err
+	}
+	if n.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: path,
+			Err: errors.New("invalid argument")}
+	}
+	return n.link, nil
+}
+
+// children returns the sorted names of given directory path's
+// children.
+func (m *memFS) children(p string) []string {
+	prefix := strings.TrimSuffix(p, string(fp.Separator)) +
+		string(fp.Separator)
+	cc := []string{}
+	for k := range m.nn {
+		if k == p || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if strings.Contains(k[len(prefix):], string(fp.Separator)) {
+			continue
+		}
+		cc = append(cc, k[len(prefix):])
+	}
+	sort.Strings(cc)
+	return cc
+}
+
+func (m *memFS) readDir(path string) ([]fs.DirEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	p, n, err := m.node("open", path)
+	if err != nil {
+		return nil, err
+	}
+	if !n.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdirent", Path: path,
+			Err: errors.New("not a directory")}
+	}
+	ee := []fs.DirEntry{}
+	for _, c := range m.children(p) {
+		cp := fp.Join(p, c)
+		ee = append(ee, fs.FileInfoToDirEntry(m.nn[cp].info(cp)))
+	}
+	return ee, nil
+}
+
+func (m *memFS) readFile(path string) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, n, err := m.node("open", path)
+	if err != nil {
+		return nil, err
+	}
+	if n.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: path,
+			Err: errors.New("is a directory")}
+	}
+	return append([]byte(nil), n.data...), nil
+}
+
+func (m *memFS) writeFile(path string, bb []byte, mode fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	p, n, err := m.node("open", path)
+	if err == nil {
+		if n.mode.IsDir() {
+			return &fs.PathError{Op: "open", Path: path,
+				Err: errors.New("is a directory")}
+		}
+		n.data, n.modTime = append([]byte(nil), bb...), time.Now()
+		return nil
+	}
+	if p == "" {
+		return err
+	}
+	if err := m.parent("open", p); err != nil {
+		return err
+	}
+	m.nn[p] = &memNode{data: append([]byte(nil), bb...),
+		mode: mode.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) chmod(path string, mode fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, n, err := m.node("chmod", path)
+	if err != nil {
+		return err
+	}
+	n.mode = n.mode.Type() | mode.Perm()
+	return nil
+}
+
+func (m *memFS) chtimes(path string, _, mtime time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, n, err := m.node("chtimes", path)
+	if err != nil {
+		return err
+	}
+	n.modTime = mtime
+	return nil
+}
+
+// walk has the semantics of filepath.Walk.
+func (m *memFS) walk(root string, fn fp.WalkFunc) error {
+	info, err := m.stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = m.walkDir(root, info, fn)
+	}
+	if err == fp.SkipDir {
+		return nil
+	}
+	return err
+}
+
+func (m *memFS) walkDir(path string, info fs.FileInfo, fn fp.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+	ee, err := m.readDir(path)
+	err1 := fn(path, info, err)
+	if err != nil || err1 != nil {
+		return err1
+	}
+	for _, e := range ee {
+		child := fp.Join(path, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			if err := fn(child, info, err); err != nil &&
+				err != fp.SkipDir {
+				return err
+			}
+			continue
+		}
+		err = m.walkDir(child, info, fn)
+		if err != nil {
+			if !info.IsDir() || err != fp.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (n *memNode) info(path string) fs.FileInfo {
+	return &memInfo{name: fp.Base(path), size: int64(len(n.data)),
+		mode: n.mode, modTime: n.modTime}
+}
+
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *memInfo) Name() string       { return i.name }
+func (i *memInfo) Size() int64        { return i.size }
+func (i *memInfo) Mode() fs.FileMode  { return i.mode }
+func (i *memInfo) ModTime() time.Time { return i.modTime }
+func (i *memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memInfo) Sys() interface{}   { return nil }
+
+// open returns an io/fs file for given path of m.
+func (m *memFS) open(path string) (fs.File, error) {
+	info, err := m.stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		ee, err := m.readDir(path)
+		if err != nil {
+			return nil, err
+		}
+		return &memDir{info: info, ee: ee}, nil
+	}
+	bb, err := m.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{info: info, data: bb}, nil
+}
+
+type memFile struct {
+	info fs.FileInfo
+	data []byte
+	off  int
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+func (f *memFile) Read(bb []byte) (int, error) {
+	if f.off >= len(f.data) {
+		return 0, io.EOF
+	}
+	n := copy(bb, f.data[f.off:])
+	f.off += n
+	return n, nil
+}
+
+type memDir struct {
+	info fs.FileInfo
+	ee   []fs.DirEntry
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(),
+		Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		ee := d.ee
+		d.ee = nil
+		return ee, nil
+	}
+	if len(d.ee) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.ee) {
+		n = len(d.ee)
+	}
+	ee := d.ee[:n]
+	d.ee = d.ee[n:]
+	return ee, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"errors"
+	gofs "io/fs"
+	"os"
+	fp "path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
+)
+
+type MemDir struct{ Suite }
+
+func (s *MemDir) SetUp(t *T) { t.Parallel() }
+
+func (s *MemDir) Doesnt_touch_the_disk(t *T) {
+	td := tfs.NewMem(t).Tmp()
+	td.MkFile("a.txt", []byte("a"))
+
+	_, err := os.Stat(td.Path())
+	t.ErrIs(err, os.ErrNotExist)
+	t.Eq("a", string(td.FileContent("a.txt")))
+}
+
+func (s *MemDir) Has_unique_temporary_directories(t *T) {
+	fs := tfs.NewMem(t)
+	t.Not.True(fs.Tmp().Path() == fs.Tmp().Path())
+}
+
+func (s *MemDir) Supports_the_dir_api(t *T) {
+	fs := tfs.NewMem(t)
+	td := fs.Tmp()
+	nested, undo := td.Mk("a", "b")
+	nested.MkPkgFile("b", []byte("func B() {}"))
+	td.FromTxtar([]byte("-- c/c.txt --\nc\n"))
+	t.Contains(string(nested.FileContent("b.go")), "package b")
+
+	cp := fs.Tmp()
+	td.Child("a").Copy(cp)
+	t.True(cp.Child("a").Eq(td.Child("a")))
+	t.True(cp.Child("a").Diff(td.Child("a")).Empty())
+
+	mt := td.FileMod(fp.Join("c", "c.txt"))
+	time.Sleep(1 * time.Millisecond)
+	td.Touch(fp.Join("c", "c.txt"))
+	t.True(mt.Before(td.FileMod(fp.Join("c", "c.txt"))))
+
+	undo()
+	t.Eq("-- c/c.txt --\nc\n", string(td.ToTxtar()))
+}
+
+func (s *MemDir) Fatales_changing_the_working_directory(t *T) {
+	td, failed := tfs.NewMem(t).Tmp(), false
+	t.Mock().Logger(func(i ...interface{}) {})
+	t.Mock().Canceler(func() { failed = true })
+
+	td.CWD()
+
+	t.True(failed)
+}
+
+func (s *MemDir) Provides_an_io_fs_view(t *T) {
+	td := tfs.NewMem(t).Tmp()
+	td.FromTxtar([]byte(fxTxtar))
+
+	t.FatalOn(fstest.TestFS(td.FS(), "go.mod", "pkg/pkg.go", "empty"))
+}
+
+func (s *MemDir) Provides_a_writable_io_fs_view(t *T) {
+	td := tfs.NewMem(t).Tmp()
+	fs := td.FS()
+
+	t.FatalOn(fs.MkdirAll("a/b", 0711))
+	t.FatalOn(fs.WriteFile("a/b/c.txt", []byte("c"), 0644))
+	t.Eq("c", string(td.FileContent(fp.Join("a", "b", "c.txt"))))
+	t.FatalOn(fs.Remove("a/b/c.txt"))
+	_, err := fs.Stat("a/b/c.txt")
+	t.ErrIs(err, gofs.ErrNotExist)
+	t.ErrIs(fs.WriteFile("../x", nil, 0644), gofs.ErrInvalid)
+}
+
+func (s *MemDir) View_of_a_disk_directory_is_an_io_fs(t *T) {
+	td := t.FS().Tmp()
+	td.FromTxtar([]byte(fxTxtar))
+
+	t.FatalOn(fstest.TestFS(td.FS(), "go.mod", "pkg/pkg.go", "empty"))
+	_, err := td.FS().Open("missing")
+	t.True(errors.Is(err, gofs.ErrNotExist))
+}
+
+func (s *MemDir) Data_checks_its_directory_in_memory(t *T) {
+	fs, disk := tfs.NewMem(t), t.FS().Tmp()
+	_, undo := fs.Dir(disk.Path())
+	undo()
+
+	td, _ := fs.Data()
+
+	t.Eq("testdata", fp.Base(td.Path()))
+}
+
+func TestMemDir(t *testing.T) {
+	t.Parallel()
+	Run(&MemDir{}, t)
+}
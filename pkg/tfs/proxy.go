@@ -0,0 +1,273 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	fp "path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WithModuleProxy makes the go commands executed by given file system's
+// directories, e.g. by [Dir.MkTidy] or [Dir.MkVendor], resolve modules
+// exclusively from given directory which is expected to have the
+// layout of a GOPROXY (see [Dir.MkProxy] and [FS.ModCache]).  Hence
+// throwaway test modules importing other modules can be tidied and
+// built without network access:
+//
+//	proxy := t.FS().Tmp()
+//	proxy.MkProxy("v1.0.0", libDir)
+//	mod := t.FS().WithModuleProxy(proxy).Tmp()
+//	mod.MkMod("example.com/app")
+//	mod.MkPkgFile("app", []byte(`import "example.com/lib"`))
+//	mod.MkTidy() // resolves example.com/lib@v1.0.0 from proxy
+//
+// Downloaded modules are kept in a test specific module cache.
+// Checksum database lookups are disabled while the flags of an
+// inherited GOFLAGS environment variable are kept.
+func (fs *FS) WithModuleProxy(dir Pather) *FS {
+	fs.t.GoT().Helper()
+	fs.proxy = dir.Path()
+	fs.modCache = fs.t.GoT().TempDir()
+	return fs
+}
+
+// ModCache returns the download directory of the user's module cache
+// which has the layout of a GOPROXY; i.e. it may be passed to
+// [FS.WithModuleProxy] to serve already downloaded real modules
+// offline.  ModCache fatales associated test if the module cache
+// can't be determined or doesn't exist.
+func (fs *FS) ModCache() *Dir {
+	fs.t.GoT().Helper()
+	bb, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		fs.t.Fatalf("gounit: fs: mod-cache: %v", err)
+	}
+	path := fp.Join(strings.TrimSpace(string(bb)), "cache", "download")
+	if _, err := fs.tls().Stat(path); err != nil {
+		fs.t.Fatalf("gounit: fs: mod-cache: %v", err)
+	}
+	return &Dir{t: fs.t, path: path, fs: fs.tls, fsys: fs}
+}
+
+// goEnv returns the environment of go commands executed in given
+// directory d.
+func (d *Dir) goEnv() []string {
+	env := os.Environ()
+	if d.fsys == nil || d.fsys.proxy == "" {
+		return env
+	}
+	return append(env,
+		"GOPROXY="+fileURL(d.fsys.proxy),
+		"GOFLAGS="+strings.TrimSpace(
+			os.Getenv("GOFLAGS")+" -mod=mod -modcacherw"),
+		"GOMODCACHE="+d.fsys.modCache,
+		"GOSUMDB=off",
+		"GOWORK=off",
+		"GOTOOLCHAIN=local",
+	)
+}
+
+func fileURL(path string) string {
+	path = fp.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // windows drive letter
+	}
+	return "file://" + path
+}
+
+// MkVendor copies the dependencies of given module directory d into
+// its vendor directory by executing "go mod vendor".  MkVendor fatales
+// associated test if the command fails.  Returned undo removes the
+// vendor directory and panics if its execution fails.
+func (d *Dir) MkVendor() (undo func()) {
+	d.t.GoT().Helper()
+	cmd := exec.Command("go", "mod", "vendor")
+	cmd.Dir, cmd.Env = d.path, d.goEnv()
+	if stdout, err := cmd.CombinedOutput(); err != nil {
+		d.t.Fatalf("gounit: fs: dir: vendor: %v:\n%s", err, stdout)
+	}
+	return d.autoUndo(func() {
+		if err := d.fs().RemoveAll(fp.Join(d.path, "vendor")); err != nil {
+			panic(fmt.Sprintf("gounit: fs: dir: vendor: reset: %v", err))
+		}
+	})
+}
+
+// MkProxy makes given directory d a file based GOPROXY (see
+// [FS.WithModuleProxy]) serving each of given module directories at
+// given version.  A module's path is taken from its go.mod file while
+// nested modules and vendor directories are excluded from its zip.
+// MkProxy fatales associated test if a module has no module path or
+// any fs-operation fails.  A module version which is already served is
+// replaced but listed only once.  Returned undo restores the files
+// MkProxy has written to their previous state, i.e. added module
+// versions are removed while replaced ones are served again as before;
+// undo panics if its execution fails.
+func (d *Dir) MkProxy(version string, modules ...Pather) (undo func()) {
+	d.t.GoT().Helper()
+	pf := &proxyFiles{d: d, prev: map[string][]byte{}}
+	for _, m := range modules {
+		mod := &Dir{t: d.t, fs: d.fs, fsys: d.fsys, path: m.Path()}
+		path := mod.moduleName()
+		if path == "" {
+			d.t.Fatalf("gounit: fs: dir: proxy: %s: missing module",
+				m.Path())
+		}
+		esc, err := escapeModPath(path)
+		if err != nil {
+			d.t.Fatalf("gounit: fs: dir: proxy: %v", err)
+		}
+		v := fp.Join(d.path, fp.FromSlash(esc), "@v")
+		if err := d.fs().MkdirAll(v, 0711); err != nil {
+			d.t.Fatalf("gounit: fs: dir: proxy: %v", err)
+		}
+		base := fp.Join(v, version)
+		pf.write(base+".mod", mod.FileContent("go.mod"))
+		pf.write(base+".info", []byte(fmt.Sprintf(
+			`{"Version":%q,"Time":%q}`, version,
+			time.Now().UTC().Format(time.RFC3339))))
+		pf.write(base+".zip", mod.modZip(path, version))
+		list, _ := d.fs().ReadFile(fp.Join(v, "list"))
+		if isListed(list, version) {
+			continue
+		}
+		pf.write(fp.Join(v, "list"),
+			append(list, []byte(version+"\n")...))
+	}
+	return d.autoUndo(pf.restore)
+}
+
+// isListed reports if given version is a line of given version list.
+func isListed(list []byte, version string) bool {
+	for _, v := range strings.Split(string(list), "\n") {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyFiles writes the files of a module proxy and keeps the previous
+// content of each written file to restore it.
+type proxyFiles struct {
+	d *Dir
+
+	// written are the written files in the order they were written
+	// first.
+	written []string
+
+	// prev maps a written file to its previous content which is nil
+	// if it didn't exist.
+	prev map[string][]byte
+}
+
+func (pf *proxyFiles) write(path string, bb []byte) {
+	if _, ok := pf.prev[path]; !ok {
+		prev, err := pf.d.fs().ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			pf.d.t.Fatalf("gounit: fs: dir: proxy: %v", err)
+		}
+		if err == nil && prev == nil {
+			prev = []byte{}
+		}
+		pf.written, pf.prev[path] = append(pf.written, path), prev
+	}
+	if err := pf.d.fs().WriteFile(path, bb, 0644); err != nil {
+		pf.d.t.Fatalf("gounit: fs: dir: proxy: %v", err)
+	}
+}
+
+// restore removes written files which didn't exist before and restores
+// the previous content of the others.
+func (pf *proxyFiles) restore() {
+	for _, path := range pf.written {
+		var err error
+		if pf.prev[path] == nil {
+			err = pf.d.fs().Remove(path)
+		} else {
+			err = pf.d.fs().WriteFile(path, pf.prev[path], 0644)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("gounit: fs: dir: proxy: reset: %v", err))
+		}
+	}
+}
+
+// modZip returns the module zip of given module directory d for given
+// module path and version.
+func (d *Dir) modZip(path, version string) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	prefix := path + "@" + version + "/"
+	err := d.fs().Walk(d.path, func(
+		p string, info fs.FileInfo, err error,
+	) error {
+		if err != nil {
+			return err
+		}
+		rel, err := fp.Rel(d.path, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p == d.path {
+				return nil
+			}
+			if info.Name() == "vendor" {
+				return fp.SkipDir
+			}
+			if _, err := d.fs().Stat(fp.Join(p, "go.mod")); err == nil {
+				return fp.SkipDir // nested module
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		bb, err := d.fs().ReadFile(p)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(prefix + fp.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bb)
+		return err
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		d.t.Fatalf("gounit: fs: dir: proxy: zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// escapeModPath escapes upper case letters of given module path as
+// required by the GOPROXY protocol, i.e. "M" becomes "!m".
+func escapeModPath(path string) (string, error) {
+	b := &strings.Builder{}
+	for _, r := range path {
+		if r == '!' || r >= unicode.MaxASCII {
+			return "", fmt.Errorf("invalid module path: %s", path)
+		}
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
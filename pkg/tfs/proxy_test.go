@@ -0,0 +1,142 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"os"
+	"os/exec"
+	fp "path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
+)
+
+type ModuleProxy struct{ Suite }
+
+func (s *ModuleProxy) SetUp(t *T) { t.Parallel() }
+
+// mkLib creates a module "example.com/Lib" in a "lib" directory of
+// given file system which exports the function Hello.
+func mkLib(t *T) *tfs.Dir {
+	lib, _ := t.FS().Tmp().Mk("lib")
+	lib.MkMod("example.com/Lib")
+	lib.MkPkgFile("lib", []byte(
+		"func Hello() string { return \"hello\" }\n"))
+	nested, _ := lib.Mk("nested")
+	nested.MkMod("example.com/Lib/nested")
+	return lib
+}
+
+func (s *ModuleProxy) Serves_given_modules_at_given_version(t *T) {
+	proxy := t.FS().Tmp()
+	proxy.MkProxy("v1.2.3", mkLib(t))
+
+	v := fp.Join("example.com", "!lib", "@v")
+	t.Eq("v1.2.3\n", string(proxy.FileContent(fp.Join(v, "list"))))
+	t.Contains(string(proxy.FileContent(fp.Join(v, "v1.2.3.info"))),
+		`"Version":"v1.2.3"`)
+	t.Eq("module example.com/Lib",
+		string(proxy.FileContent(fp.Join(v, "v1.2.3.mod"))))
+	zip := string(proxy.FileContent(fp.Join(v, "v1.2.3.zip")))
+	t.Contains(zip, "example.com/Lib@v1.2.3/lib.go")
+	t.Not.Contains(zip, "nested")
+}
+
+func (s *ModuleProxy) Undo_removes_added_versions_only(t *T) {
+	proxy, lib := t.FS().Tmp(), mkLib(t)
+	proxy.MkProxy("v1.0.0", lib)
+	undo := proxy.MkProxy("v1.1.0", lib)
+
+	undo()
+
+	v := fp.Join(proxy.Path(), "example.com", "!lib", "@v")
+	t.Eq("v1.0.0\n", string(proxy.FileContent(
+		fp.Join("example.com", "!lib", "@v", "list"))))
+	_, err := os.Stat(fp.Join(v, "v1.1.0.zip"))
+	t.ErrIs(err, os.ErrNotExist)
+	_, err = os.Stat(fp.Join(v, "v1.0.0.zip"))
+	t.FatalOn(err)
+}
+
+func (s *ModuleProxy) Lists_a_served_version_only_once(t *T) {
+	proxy, lib := t.FS().Tmp(), mkLib(t)
+	proxy.MkProxy("v1.0.0", lib)
+	undo := proxy.MkProxy("v1.0.0", lib)
+
+	list := fp.Join("example.com", "!lib", "@v", "list")
+	t.Eq("v1.0.0\n", string(proxy.FileContent(list)))
+	undo()
+	t.Eq("v1.0.0\n", string(proxy.FileContent(list)))
+	_, err := os.Stat(fp.Join(proxy.Path(),
+		"example.com", "!lib", "@v", "v1.0.0.zip"))
+	t.FatalOn(err)
+}
+
+func (s *ModuleProxy) Undo_restores_replaced_versions(t *T) {
+	proxy, lib := t.FS().Tmp(), mkLib(t)
+	proxy.MkProxy("v1.0.0", lib)
+	lib.MkFile("added.go", []byte("package lib\n"))
+	undo := proxy.MkProxy("v1.0.0", lib)
+	zip := fp.Join("example.com", "!lib", "@v", "v1.0.0.zip")
+	t.Contains(string(proxy.FileContent(zip)), "added.go")
+
+	undo()
+
+	t.Not.Contains(string(proxy.FileContent(zip)), "added.go")
+	t.Contains(string(proxy.FileContent(zip)), "lib.go")
+}
+
+func (s *ModuleProxy) Tidies_and_builds_offline(t *T) {
+	proxy := t.FS().Tmp()
+	proxy.MkProxy("v1.0.0", mkLib(t))
+	app, _ := t.FS().WithModuleProxy(proxy).Tmp().Mk("app")
+	app.MkMod("example.com/app")
+	app.MkPkgFile("app", []byte(
+		"import \"example.com/Lib\"\n\nvar Hello = lib.Hello\n"))
+
+	app.MkTidy()
+
+	t.Contains(string(app.FileContent("go.mod")),
+		"example.com/Lib v1.0.0")
+	t.Contains(string(app.FileContent("go.sum")), "example.com/Lib")
+	app.MkVendor()
+	t.Contains(string(app.FileContent(
+		fp.Join("vendor", "modules.txt"))), "example.com/Lib v1.0.0")
+	cmd := exec.Command("go", "build", "-mod=vendor", "./...")
+	cmd.Dir = app.Path()
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOPROXY=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("build: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+}
+
+func TestModuleProxy(t *testing.T) {
+	t.Parallel()
+	Run(&ModuleProxy{}, t)
+}
+
+// TestModuleProxyGOFLAGS can't run in parallel since it sets the
+// environment.
+func TestModuleProxyGOFLAGS(t *testing.T) {
+	t.Setenv("GOFLAGS", "-tags=proxied")
+	tt := NewT(t)
+	proxy := tt.FS().Tmp()
+	proxy.MkProxy("v1.0.0", mkLib(tt))
+	app, _ := tt.FS().WithModuleProxy(proxy).Tmp().Mk("app")
+	app.MkMod("example.com/app")
+	app.MkFile("app_test.go", []byte("//go:build proxied\n\n"+
+		"package app\n\nimport (\n\t\"testing\"\n\n"+
+		"\t\"example.com/Lib\"\n)\n\n"+
+		"func TestHello(t *testing.T) { _ = lib.Hello() }\n"))
+	app.MkTidy()
+
+	r := app.GoTest("./...")
+
+	tt.FatalOn(r.Err)
+	tt.True(r.Results.Of("TestHello") != nil)
+}
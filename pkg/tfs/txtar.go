@@ -0,0 +1,265 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	fp "path/filepath"
+	"sort"
+	"strings"
+)
+
+// TxtarTidy is the line of a txtar archive's comment which makes
+// [Dir.FromTxtar] tidy each of the archive's modules (see [Dir.MkTidy]).
+const TxtarTidy = "gounit: tidy"
+
+// FromTxtar materializes the files of given txtar archive in given
+// directory d, e.g.:
+//
+//	td := t.FS().Tmp()
+//	td.FromTxtar([]byte(`a tidied module with a package
+//	gounit: tidy
+//	-- go.mod --
+//	module example.com/mod
+//	-- pkg/pkg.go --
+//	package pkg
+//	`))
+//
+// Missing directories of a file's (slash separated) name are created
+// while a name ending in a slash denotes an empty directory.  Is the
+// archive's comment containing the line [TxtarTidy] each directory
+// with a go.mod file is tidied after all files were written.  FromTxtar
+// fatales associated test if a file exists already or any fs-operation
+// fails.  Returned undo removes all created files and directories and
+// panics if its execution fails.
+func (d *Dir) FromTxtar(archive []byte) (undo func()) {
+	d.t.GoT().Helper()
+	comment, ff := parseTxtar(archive)
+	created, modules := []string{}, []string{}
+	for _, f := range ff {
+		name := fp.FromSlash(strings.TrimSuffix(f.name, "/"))
+		if name == "" || name == "." {
+			d.t.Fatalf("gounit: fs: dir: from txtar: invalid name: %q",
+				f.name)
+		}
+		dir, path := fp.Dir(name), fp.Join(d.path, name)
+		if strings.HasSuffix(f.name, "/") {
+			dir = name
+		}
+		created = append(created, d.mkdirs(dir)...)
+		if strings.HasSuffix(f.name, "/") {
+			continue
+		}
+		if _, err := d.fs().Stat(path); err == nil {
+			d.t.Fatalf("gounit: fs: dir: from txtar: %s: already exists",
+				f.name)
+		}
+		if err := d.fs().WriteFile(path, f.data, 0644); err != nil {
+			d.t.Fatalf("gounit: fs: dir: from txtar: write: %v", err)
+		}
+		created = append(created, path)
+		if fp.Base(name) == "go.mod" {
+			modules = append(modules, fp.Join(d.path, dir))
+		}
+	}
+	if hasTxtarLine(comment, TxtarTidy) {
+		for _, m := range modules {
+			sum := fp.Join(m, "go.sum")
+			_, err := d.fs().Stat(sum)
+			(&Dir{t: d.t, fs: d.fs, fsys: d.fsys, path: m}).MkTidy()
+			if err != nil {
+				created = append(created, sum)
+			}
+		}
+	}
+	return d.autoUndo(func() {
+		for i := len(created) - 1; i >= 0; i-- {
+			if err := d.fs().RemoveAll(created[i]); err != nil {
+				panic(fmt.Sprintf(
+					"gounit: fs: dir: from txtar: reset: %v", err))
+			}
+		}
+	})
+}
+
+// mkdirs creates given relative directory path in d and returns the
+// paths of the directories which didn't exist before.
+func (d *Dir) mkdirs(rel string) (created []string) {
+	if rel == "." {
+		return nil
+	}
+	path := d.path
+	for _, seg := range strings.Split(rel, string(fp.Separator)) {
+		path = fp.Join(path, seg)
+		if _, err := d.fs().Stat(path); err == nil {
+			continue
+		}
+		if err := d.fs().Mkdir(path, 0711); err != nil {
+			d.t.Fatalf("gounit: fs: dir: from txtar: create: %v", err)
+		}
+		created = append(created, path)
+	}
+	return created
+}
+
+// ToTxtar serializes the files of given directory d in lexical order
+// into a txtar archive whose file names are slash separated and
+// relative to d.  Empty directories are reported by their name with a
+// trailing slash.  ToTxtar fatales associated test on irregular files
+// or if any fs-operation fails.
+func (d *Dir) ToTxtar() []byte {
+	d.t.GoT().Helper()
+	ff := []txtarFile{}
+	err := d.fs().Walk(d.path, func(
+		path string, info fs.FileInfo, err error,
+	) error {
+		if err != nil {
+			return err
+		}
+		if path == d.path {
+			return nil
+		}
+		rel, err := fp.Rel(d.path, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			ee, err := d.fs().ReadDir(path)
+			if err != nil {
+				return err
+			}
+			if len(ee) == 0 {
+				ff = append(ff, txtarFile{name: fp.ToSlash(rel) + "/"})
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("can't handle file: %s", path)
+		}
+		bb, err := d.fs().ReadFile(path)
+		if err != nil {
+			return err
+		}
+		ff = append(ff, txtarFile{name: fp.ToSlash(rel), data: bb})
+		return nil
+	})
+	if err != nil {
+		d.t.Fatalf("gounit: fs: dir: to txtar: %v", err)
+	}
+	return formatTxtar(nil, ff)
+}
+
+// EqTxtar returns true iff given directory d contains exactly the files
+// and empty directories of given txtar archive with the archive's
+// content; otherwise false.  The archive's comment is ignored.  Note
+// the go.mod and go.sum files of a tidied module (see [TxtarTidy])
+// usually differ from the archive's go.mod.  EqTxtar fatales
+// associated test if d can't be serialized (see [Dir.ToTxtar]).
+func (d *Dir) EqTxtar(archive []byte) bool {
+	d.t.GoT().Helper()
+	_, exp := parseTxtar(archive)
+	_, got := parseTxtar(d.ToTxtar())
+	if len(exp) != len(got) {
+		return false
+	}
+	sortTxtar(exp)
+	sortTxtar(got)
+	for i := range exp {
+		if exp[i].name != got[i].name {
+			return false
+		}
+		if !bytes.Equal(exp[i].data, got[i].data) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortTxtar(ff []txtarFile) {
+	sort.Slice(ff, func(i, j int) bool { return ff[i].name < ff[j].name })
+}
+
+// txtarFile is a file of a txtar archive.
+type txtarFile struct {
+	name string
+	data []byte
+}
+
+var (
+	txtarMarker    = []byte("-- ")
+	txtarMarkerEnd = []byte(" --")
+)
+
+// parseTxtar parses given txtar archive into its comment and files.  A
+// file's data is newline terminated unless it is empty.
+func parseTxtar(archive []byte) (comment []byte, ff []txtarFile) {
+	var current *txtarFile
+	for len(archive) > 0 {
+		line := archive
+		if i := bytes.IndexByte(archive, '\n'); i >= 0 {
+			line, archive = archive[:i+1], archive[i+1:]
+		} else {
+			archive = nil
+		}
+		if name, ok := txtarName(line); ok {
+			ff = append(ff, txtarFile{name: name})
+			current = &ff[len(ff)-1]
+			continue
+		}
+		if current == nil {
+			comment = append(comment, line...)
+			continue
+		}
+		current.data = append(current.data, line...)
+	}
+	for i := range ff {
+		ff[i].data = withNewline(ff[i].data)
+	}
+	return withNewline(comment), ff
+}
+
+// txtarName returns the file name of given txtar file marker line and
+// true; false if given line is no marker.
+func txtarName(line []byte) (string, bool) {
+	line = bytes.TrimRight(line, "\r\n")
+	if !bytes.HasPrefix(line, txtarMarker) ||
+		!bytes.HasSuffix(line, txtarMarkerEnd) ||
+		len(line) < len(txtarMarker)+len(txtarMarkerEnd) {
+		return "", false
+	}
+	name := strings.TrimSpace(string(
+		line[len(txtarMarker) : len(line)-len(txtarMarkerEnd)]))
+	return name, name != ""
+}
+
+// formatTxtar returns the txtar archive of given comment and files.
+func formatTxtar(comment []byte, ff []txtarFile) []byte {
+	bb := &bytes.Buffer{}
+	bb.Write(withNewline(comment))
+	for _, f := range ff {
+		fmt.Fprintf(bb, "-- %s --\n", f.name)
+		bb.Write(withNewline(f.data))
+	}
+	return bb.Bytes()
+}
+
+func withNewline(bb []byte) []byte {
+	if len(bb) == 0 || bb[len(bb)-1] == '\n' {
+		return bb
+	}
+	return append(bb, '\n')
+}
+
+// hasTxtarLine returns true iff given comment has given line.
+func hasTxtarLine(comment []byte, line string) bool {
+	for _, l := range strings.Split(string(comment), "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"os"
+	fp "path/filepath"
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type Txtar struct{ Suite }
+
+func (s *Txtar) SetUp(t *T) { t.Parallel() }
+
+const fxTxtar = `a module with a package and an empty directory
+-- go.mod --
+module example.com/mod
+-- empty/ --
+-- pkg/pkg.go --
+package pkg
+
+func Answer() int { return 42 }
+`
+
+func (s *Txtar) Materializes_an_archive_s_files(t *T) {
+	td := t.FS().Tmp()
+
+	td.FromTxtar([]byte(fxTxtar))
+
+	t.Eq("module example.com/mod\n", string(td.FileContent("go.mod")))
+	t.Contains(string(td.FileContent(fp.Join("pkg", "pkg.go"))),
+		"return 42")
+	t.True(td.Child("empty").Path() != "")
+}
+
+func (s *Txtar) Materialization_can_be_undone(t *T) {
+	td := t.FS().Tmp()
+	td.MkFile("keep.txt", []byte("keep"))
+	undo := td.FromTxtar([]byte(fxTxtar))
+
+	undo()
+
+	ee, err := os.ReadDir(td.Path())
+	t.FatalOn(err)
+	t.FatalIfNot(t.Eq(1, len(ee)))
+	t.Eq("keep.txt", ee[0].Name())
+}
+
+func (s *Txtar) Fatales_materialization_of_existing_file(t *T) {
+	td, failed := t.FS().Tmp(), false
+	td.MkFile("go.mod", []byte("module example.com/mod"))
+	t.Mock().Canceler(func() { failed = true })
+	t.Mock().Logger(func(i ...interface{}) {})
+
+	td.FromTxtar([]byte(fxTxtar))
+
+	t.True(failed)
+}
+
+func (s *Txtar) Serializes_a_directory_s_files(t *T) {
+	td := t.FS().Tmp()
+	td.FromTxtar([]byte(fxTxtar))
+
+	t.Eq("-- empty/ --\n-- go.mod --\nmodule example.com/mod\n"+
+		"-- pkg/pkg.go --\npackage pkg\n\nfunc Answer() int "+
+		"{ return 42 }\n", string(td.ToTxtar()))
+}
+
+func (s *Txtar) Is_equal_to_a_directory_with_same_files(t *T) {
+	td := t.FS().Tmp()
+	td.FromTxtar([]byte(fxTxtar))
+
+	t.True(td.EqTxtar([]byte(fxTxtar)))
+	t.True(td.EqTxtar(td.ToTxtar()))
+	t.Not.True(td.EqTxtar([]byte("-- go.mod --\nmodule example.com/mod")))
+
+	td.WriteContent("go.mod", []byte("module example.com/other\n"))
+	t.Not.True(td.EqTxtar([]byte(fxTxtar)))
+}
+
+func TestTxtar(t *testing.T) {
+	t.Parallel()
+	Run(&Txtar{}, t)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AutoUndo makes given file system register each undo function
+// returned by itself or by one of its directories with the cleanup of
+// associated test, i.e. the undos are executed in LIFO order after the
+// test ended:
+//
+//	fs := t.FS().AutoUndo()
+//	gd, _ := fs.Data() // removed after the test if created
+//	gd.MkFile("golden.txt", []byte("42")) // removed after the test
+//
+// A returned undo may still be called explicitly in which case it is
+// not executed again at the cleanup.  Undos failing at the cleanup
+// don't panic but are reported as error of associated test after all
+// undos ran.
+func (fs *FS) AutoUndo() *FS {
+	if fs.auto {
+		return fs
+	}
+	fs.auto = true
+	fs.t.GoT().Cleanup(func() {
+		fs.mutex.Lock()
+		defer fs.mutex.Unlock()
+		if len(fs.failed) == 0 {
+			return
+		}
+		err := fmt.Sprintf("gounit: fs: auto-undo: failed:\n%s",
+			strings.Join(fs.failed, "\n"))
+		if e, ok := fs.t.(interface {
+			Errorf(string, ...interface{})
+		}); ok {
+			e.Errorf(err)
+			return
+		}
+		fs.t.GoT().Error(err)
+	})
+	return fs
+}
+
+// autoUndo registers given undo function with the cleanup of fs's test
+// iff fs is in auto-undo mode; the returned undo is executed at most
+// once.
+func (fs *FS) autoUndo(undo func()) func() {
+	if !fs.auto || undo == nil {
+		return undo
+	}
+	once := &sync.Once{}
+	undoOnce := func() { once.Do(undo) }
+	fs.t.GoT().Cleanup(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				fs.mutex.Lock()
+				defer fs.mutex.Unlock()
+				fs.failed = append(fs.failed, fmt.Sprint(r))
+			}
+		}()
+		undoOnce()
+	})
+	return undoOnce
+}
+
+// autoUndo registers given undo with the cleanup of d's test if d's
+// file system is in auto-undo mode (see [FS.AutoUndo]).
+func (d *Dir) autoUndo(undo func()) func() {
+	if d.fsys == nil {
+		return undo
+	}
+	return d.fsys.autoUndo(undo)
+}
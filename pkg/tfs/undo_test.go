@@ -0,0 +1,68 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	fp "path/filepath"
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
+)
+
+type AutoUndo struct{ Suite }
+
+func (s *AutoUndo) SetUp(t *T) { t.Parallel() }
+
+func (s *AutoUndo) Undoes_at_the_end_of_the_test(t *T) {
+	td := t.FS().Tmp()
+	t.GoT().Run("auto", func(_t *testing.T) {
+		dir, _ := NewT(_t).FS().AutoUndo().Dir(td.Path())
+		nested, _ := dir.Mk("a", "b")
+		nested.MkFile("c.txt", []byte("c"))
+		dir.MkFile("d.txt", []byte("d"))
+		dir.FromTxtar([]byte("-- e/e.txt --\ne\n"))
+	})
+
+	ee, err := os.ReadDir(td.Path())
+	t.FatalOn(err)
+	t.Eq(0, len(ee))
+}
+
+func (s *AutoUndo) Undoes_at_most_once(t *T) {
+	td := t.FS().Tmp()
+	t.GoT().Run("auto", func(_t *testing.T) {
+		dir, _ := NewT(_t).FS().AutoUndo().Dir(td.Path())
+		undo := dir.MkFile("a.txt", []byte("a"))
+		undo()
+		dir.MkFile("a.txt", []byte("b"))
+	})
+
+	_, err := os.Stat(fp.Join(td.Path(), "a.txt"))
+	t.ErrIs(err, os.ErrNotExist)
+}
+
+func (s *AutoUndo) Reports_failing_undos_instead_of_panicking(t *T) {
+	var errs string
+	t.GoT().Run("auto", func(_t *testing.T) {
+		tt := NewT(_t)
+		tt.Mock().Errorer(func(i ...interface{}) { errs += fmt.Sprint(i...) })
+		fx := tfs.NewFX(tt)
+		fx.AutoUndo().Tmp().MkFile("a.txt", []byte("a"))
+		fx.Mock().Remove(func(string) error {
+			return errors.New("mock-err")
+		})
+	})
+
+	t.StarMatched(errs, "gounit: fs: auto-undo: failed:", "mock-err")
+}
+
+func TestAutoUndo(t *testing.T) {
+	t.Parallel()
+	Run(&AutoUndo{}, t)
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs
+
+import (
+	"bytes"
+	"fmt"
+	fp "path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minWorkVersion is the minimum go version of a go.work file.
+const minWorkVersion = "1.18"
+
+// MkWork adds to given directory d a go.work file using given module
+// directories whereas its go version is the highest go version of given
+// modules' go.mod files but at least 1.18.  MkWork fatales associated
+// test if d already has a go.work file or an fs-operation fails.
+// Returned undo removes the go.work file and panics if its execution
+// fails.
+func (d *Dir) MkWork(modules ...Pather) (undo func()) {
+	d.t.GoT().Helper()
+	version, uu := minWorkVersion, []string{}
+	for _, m := range modules {
+		mod := &Dir{t: d.t, fs: d.fs, fsys: d.fsys, path: m.Path()}
+		if v := goVersion(mod.FileContent("go.mod")); cmpVersion(
+			v, version) > 0 {
+			version = v
+		}
+		uu = append(uu, d.relModPath(m.Path()))
+	}
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "go %s\n", version)
+	if len(uu) > 0 {
+		b.WriteString("\nuse (\n")
+		for _, u := range uu {
+			fmt.Fprintf(b, "\t%s\n", u)
+		}
+		b.WriteString(")\n")
+	}
+	return d.MkFile("go.work", []byte(b.String()))
+}
+
+// MkGoVersion sets the go directive of the go.mod file in given
+// directory d to given version, e.g. "1.19".  MkGoVersion fatales
+// associated test if d has no go.mod file or an fs-operation fails.
+// Returned undo restores the previous go.mod content and panics if its
+// execution fails.
+func (d *Dir) MkGoVersion(version string) (undo func()) {
+	d.t.GoT().Helper()
+	return d.editGoMod("go-version", func(bb []byte) []byte {
+		directive := []byte("go " + version)
+		if reGoDirective.Match(bb) {
+			return reGoDirective.ReplaceAllLiteral(bb, directive)
+		}
+		return appendGoMod(bb, directive)
+	})
+}
+
+// MkRequire adds a requirement of given module path at given version
+// to the go.mod file in given directory d or updates the version of an
+// existing requirement.  MkRequire fatales associated test if d has no
+// go.mod file or an fs-operation fails.  Returned undo restores the
+// previous go.mod content and panics if its execution fails.
+func (d *Dir) MkRequire(path, version string) (undo func()) {
+	d.t.GoT().Helper()
+	return d.editGoMod("require", func(bb []byte) []byte {
+		re := regexp.MustCompile(`^(\s*(?:require\s+)?` +
+			regexp.QuoteMeta(path) + `\s+)\S+`)
+		if updated, ok := updateGoMod(bb, "require", re, version); ok {
+			return updated
+		}
+		return appendGoMod(bb, []byte(fmt.Sprintf(
+			"require %s %s", path, version)))
+	})
+}
+
+// goModLine is a line of a go.mod file which is flagged if it is a
+// directive of a particular kind, e.g. a require directive, or a line
+// of a block of such directives.
+type goModLine struct {
+	bb        []byte
+	directive bool
+}
+
+var reGoModBlock = regexp.MustCompile(`^\s*(\w+)\s*\(\s*(//.*)?$`)
+
+// directiveLines splits given go.mod content into its lines flagging
+// the lines of given directive kind, e.g. "require".
+func directiveLines(bb []byte, kind string) []goModLine {
+	ll, block := []goModLine{}, ""
+	for _, l := range bytes.SplitAfter(bb, []byte("\n")) {
+		trimmed := bytes.TrimSpace(l)
+		switch {
+		case block == "" && reGoModBlock.Match(trimmed):
+			block = string(reGoModBlock.FindSubmatch(trimmed)[1])
+		case block != "" && bytes.HasPrefix(trimmed, []byte(")")):
+			block = ""
+		case block == kind:
+			ll = append(ll, goModLine{bb: l, directive: true})
+			continue
+		case block == "":
+			ll = append(ll, goModLine{bb: l, directive: bytes.HasPrefix(
+				trimmed, []byte(kind+" "))})
+			continue
+		}
+		ll = append(ll, goModLine{bb: l})
+	}
+	return ll
+}
+
+// updateGoMod replaces in each line of given go.mod content which is a
+// directive of given kind and is matched by given regular expression
+// the match's remainder after its first group with given value.  The
+// updated content is returned and true iff a line was updated.
+func updateGoMod(
+	bb []byte, kind string, re *regexp.Regexp, value string,
+) ([]byte, bool) {
+	ll, found := directiveLines(bb, kind), false
+	for i, l := range ll {
+		m := re.FindSubmatchIndex(l.bb)
+		if !l.directive || m == nil {
+			continue
+		}
+		ll[i].bb = append(append(append([]byte{}, l.bb[:m[3]]...),
+			value...), l.bb[m[1]:]...)
+		found = true
+	}
+	if !found {
+		return bb, false
+	}
+	bb = nil
+	for _, l := range ll {
+		bb = append(bb, l.bb...)
+	}
+	return bb, true
+}
+
+// MkModReplace adds to the go.mod file in given directory d a replace
+// directive replacing given module path with given module directory
+// which is referenced relative to d.  An existing replacement of path,
+// also of a particular version of it, is updated.  MkModReplace fatales associated test if d has no go.mod
+// file or an fs-operation fails.  Returned undo restores the previous
+// go.mod content and panics if its execution fails.
+func (d *Dir) MkModReplace(path string, dir Pather) (undo func()) {
+	d.t.GoT().Helper()
+	replacement := d.relModPath(dir.Path())
+	return d.editGoMod("replace", func(bb []byte) []byte {
+		re := regexp.MustCompile(`^(\s*(?:replace\s+)?` +
+			regexp.QuoteMeta(path) + `(?:\s+\S+)?\s+=>\s+)` +
+			`\S+(?:[ \t]+[^\s/]+)?`)
+		if updated, ok := updateGoMod(
+			bb, "replace", re, replacement); ok {
+
+			return updated
+		}
+		return appendGoMod(bb, []byte(fmt.Sprintf(
+			"replace %s => %s", path, replacement)))
+	})
+}
+
+var reGoDirective = regexp.MustCompile(`(?m)^go\s+\S+`)
+
+// editGoMod replaces the content of d's go.mod file with the result of
+// given edit function.
+func (d *Dir) editGoMod(op string, edit func([]byte) []byte) func() {
+	d.t.GoT().Helper()
+	fl := fp.Join(d.path, "go.mod")
+	bb, err := d.fs().ReadFile(fl)
+	if err != nil {
+		d.t.Fatalf("gounit: fs: dir: %s: %v", op, err)
+	}
+	if err := d.fs().WriteFile(fl, edit(bb), 0644); err != nil {
+		d.t.Fatalf("gounit: fs: dir: %s: %v", op, err)
+	}
+	return d.autoUndo(func() {
+		if err := d.fs().WriteFile(fl, bb, 0644); err != nil {
+			panic(fmt.Sprintf("gounit: fs: dir: %s: reset: %v", op, err))
+		}
+	})
+}
+
+func appendGoMod(bb, line []byte) []byte {
+	bb = bytes.TrimRight(bb, "\n")
+	return append(append(bb, '\n', '\n'), append(line, '\n')...)
+}
+
+// relModPath returns given directory path relative to d as needed by
+// go.work use and go.mod replace directives.
+func (d *Dir) relModPath(path string) string {
+	rel, err := fp.Rel(d.path, path)
+	if err != nil {
+		d.t.Fatalf("gounit: fs: dir: relative module path: %v", err)
+	}
+	rel = fp.ToSlash(rel)
+	if rel == "." || strings.HasPrefix(rel, "../") {
+		return rel
+	}
+	return "./" + rel
+}
+
+// goVersion returns the go directive's version of given go.mod content;
+// the empty string if there is none.
+func goVersion(mod []byte) string {
+	directive := reGoDirective.Find(mod)
+	if directive == nil {
+		return ""
+	}
+	return strings.TrimSpace(string(directive[len("go"):]))
+}
+
+// cmpVersion compares two go versions like "1.19", "1.21.3" or
+// "1.21rc1" numerically ignoring pre-release suffixes and returns -1, 0,
+// or 1.
+func cmpVersion(a, b string) int {
+	aa, bb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aa) || i < len(bb); i++ {
+		var x, y int
+		if i < len(aa) {
+			x = versionNumber(aa[i])
+		}
+		if i < len(bb) {
+			y = versionNumber(bb[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionNumber returns the number of given go version component
+// without a pre-release suffix, e.g. 21 for "21rc1".
+func versionNumber(component string) int {
+	n := strings.IndexFunc(component, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if n >= 0 {
+		component = component[:n]
+	}
+	x, _ := strconv.Atoi(component)
+	return x
+}
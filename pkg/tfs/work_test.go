@@ -0,0 +1,220 @@
+// Copyright (c) 2022 Stephan Lukits. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tfs_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
+)
+
+type Workspace struct{ Suite }
+
+func (s *Workspace) SetUp(t *T) { t.Parallel() }
+
+// goCmd executes the go command with given arguments in given directory
+// and fatales given test if the execution fails.
+func goCmd(t *T, dir *tfs.Dir, args ...string) string {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir.Path()
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOPROXY=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func (s *Workspace) Uses_given_modules_relative_to_its_directory(t *T) {
+	root := t.FS().Tmp()
+	app, _ := root.Mk("app")
+	lib, _ := root.Mk("libs", "lib")
+	app.MkMod("example.com/app")
+	lib.MkMod("example.com/lib")
+
+	root.MkWork(app, lib)
+
+	t.Eq("go 1.18\n\nuse (\n\t./app\n\t./libs/lib\n)\n",
+		string(root.FileContent("go.work")))
+}
+
+func (s *Workspace) Has_the_highest_go_version_of_its_modules(t *T) {
+	root := t.FS().Tmp()
+	a, _ := root.Mk("a")
+	b, _ := root.Mk("b")
+	a.MkMod("example.com/a")
+	b.MkMod("example.com/b")
+	a.MkGoVersion("1.9")
+	b.MkGoVersion("1.19")
+
+	root.MkWork(a, b)
+
+	t.True(strings.HasPrefix(
+		string(root.FileContent("go.work")), "go 1.19\n"))
+}
+
+func (s *Workspace) Compares_pre_release_go_versions(t *T) {
+	root := t.FS().Tmp()
+	a, _ := root.Mk("a")
+	b, _ := root.Mk("b")
+	a.MkMod("example.com/a")
+	b.MkMod("example.com/b")
+	a.MkGoVersion("1.20")
+	b.MkGoVersion("1.21rc1")
+
+	root.MkWork(a, b)
+
+	t.True(strings.HasPrefix(
+		string(root.FileContent("go.work")), "go 1.21rc1\n"))
+}
+
+func (s *Workspace) Builds_nested_modules(t *T) {
+	root := t.FS().Tmp()
+	root.MkMod("example.com/app")
+	root.MkFile("main.go", []byte("package main\n\n"+
+		"import \"example.com/lib\"\n\nfunc main() { lib.Hello() }\n"))
+	lib, _ := root.Mk("lib")
+	lib.MkMod("example.com/lib")
+	lib.MkPkgFile("lib", []byte("func Hello() {}\n"))
+
+	root.MkWork(root, lib)
+
+	t.Contains(string(root.FileContent("go.work")), "\t.\n")
+	goCmd(t, root, "build", "./...")
+}
+
+func (s *Workspace) Undo_removes_the_go_work_file(t *T) {
+	root := t.FS().Tmp()
+
+	root.MkWork()()
+
+	_, err := os.Stat(root.Path() + "/go.work")
+	t.ErrIs(err, os.ErrNotExist)
+}
+
+func TestWorkspace(t *testing.T) {
+	t.Parallel()
+	Run(&Workspace{}, t)
+}
+
+type GoMod struct{ Suite }
+
+func (s *GoMod) SetUp(t *T) { t.Parallel() }
+
+func (s *GoMod) Sets_and_updates_the_go_version(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/mod")
+
+	mod.MkGoVersion("1.18")
+	t.Eq("module example.com/mod\n\ngo 1.18\n",
+		string(mod.FileContent("go.mod")))
+
+	mod.MkGoVersion("1.19")
+	t.Eq("module example.com/mod\n\ngo 1.19\n",
+		string(mod.FileContent("go.mod")))
+}
+
+func (s *GoMod) Adds_and_updates_requirements(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/mod")
+
+	mod.MkRequire("example.com/lib", "v1.0.0")
+	mod.MkRequire("example.com/other", "v0.1.0")
+	mod.MkRequire("example.com/lib", "v1.1.0")
+
+	t.Eq("module example.com/mod\n\n"+
+		"require example.com/lib v1.1.0\n\n"+
+		"require example.com/other v0.1.0\n",
+		string(mod.FileContent("go.mod")))
+}
+
+func (s *GoMod) Updates_required_versions_only(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkFile("go.mod", []byte("module example.com/mod\n\n"+
+		"require (\n\texample.com/lib v1.0.0\n)\n\n"+
+		"replace (\n\texample.com/lib v1.0.0 => ../lib\n)\n\n"+
+		"exclude example.com/lib v0.1.0\n"))
+
+	mod.MkRequire("example.com/lib", "v1.1.0")
+
+	t.Eq("module example.com/mod\n\n"+
+		"require (\n\texample.com/lib v1.1.0\n)\n\n"+
+		"replace (\n\texample.com/lib v1.0.0 => ../lib\n)\n\n"+
+		"exclude example.com/lib v0.1.0\n",
+		string(mod.FileContent("go.mod")))
+}
+
+func (s *GoMod) Replaces_modules_by_relative_directories(t *T) {
+	root := t.FS().Tmp()
+	app, _ := root.Mk("app")
+	lib, _ := root.Mk("lib")
+	app.MkMod("example.com/app")
+	lib.MkMod("example.com/lib")
+
+	app.MkModReplace("example.com/lib", lib)
+	t.Contains(string(app.FileContent("go.mod")),
+		"replace example.com/lib => ../lib\n")
+
+	nested, _ := app.Mk("nested")
+	app.MkModReplace("example.com/lib", nested)
+	t.Not.Contains(string(app.FileContent("go.mod")), "../lib")
+	t.Contains(string(app.FileContent("go.mod")),
+		"replace example.com/lib => ./nested\n")
+}
+
+func (s *GoMod) Updates_versioned_and_block_replacements(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkFile("go.mod", []byte("module example.com/mod\n\n"+
+		"require example.com/lib v1.0.0\n\n"+
+		"replace example.com/lib v1.0.0 => example.com/fork v1.0.1\n\n"+
+		"replace (\n\texample.com/lib => ../lib // local\n"+
+		"\texample.com/other => ../other\n)\n"))
+	dir, _ := mod.Mk("lib")
+
+	mod.MkModReplace("example.com/lib", dir)
+
+	t.Eq("module example.com/mod\n\n"+
+		"require example.com/lib v1.0.0\n\n"+
+		"replace example.com/lib v1.0.0 => ./lib\n\n"+
+		"replace (\n\texample.com/lib => ./lib // local\n"+
+		"\texample.com/other => ../other\n)\n",
+		string(mod.FileContent("go.mod")))
+}
+
+func (s *GoMod) Builds_with_replaced_requirements(t *T) {
+	root := t.FS().Tmp()
+	app, _ := root.Mk("app")
+	lib, _ := root.Mk("lib")
+	app.MkMod("example.com/app")
+	app.MkPkgFile("app", []byte(
+		"import \"example.com/lib\"\n\nvar Hello = lib.Hello\n"))
+	lib.MkMod("example.com/lib")
+	lib.MkPkgFile("lib", []byte("func Hello() {}\n"))
+
+	app.MkRequire("example.com/lib", "v0.0.0")
+	app.MkModReplace("example.com/lib", lib)
+
+	goCmd(t, app, "build", "./...")
+}
+
+func (s *GoMod) Undo_restores_the_previous_go_mod(t *T) {
+	mod := t.FS().Tmp()
+	mod.MkMod("example.com/mod")
+	undo := mod.MkGoVersion("1.19")
+	mod.MkRequire("example.com/lib", "v1.0.0")()
+
+	undo()
+
+	t.Eq("module example.com/mod", string(mod.FileContent("go.mod")))
+}
+
+func TestGoMod(t *testing.T) {
+	t.Parallel()
+	Run(&GoMod{}, t)
+}
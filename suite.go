@@ -5,10 +5,18 @@
 package gounit
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"runtime"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/slukits/gounit/pkg/testjson"
 )
 
 // Suite implements the private methods of the SuiteEmbedder interface.
@@ -39,17 +47,35 @@ type Suite struct {
 	value           reflect.Value
 	rType           reflect.Type
 	setUp, tearDown *reflect.Method
+	deadline        time.Duration
 }
 
 // newFinalizer returns a function which may be used to register at
 // t.Cleanup which calls suite's (given) Finalize-method with provided
-// values.
-func newFinalizer(
-	method *reflect.Method, suite, gounitF reflect.Value,
-) func() {
-	return func() {
-		method.Func.Call([]reflect.Value{suite, gounitF})
+// S-instance.
+func (s *Suite) newFinalizer(method *reflect.Method, st *S) func() {
+	return func() { s.sWithin(method, st) }
+}
+
+// sWithin calls given Init- or Finalize-method with given S-instance
+// within the suite's deadline (see [SuiteDeadliner]).  An expired
+// deadline is logged and cancels the suite's test-run.
+func (s *Suite) sWithin(method *reflect.Method, st *S) {
+	if s.deadline > 0 {
+		st.canceled = &atomic.Bool{}
 	}
+	failure := within(s.deadline, st.expired, func(ctx context.Context) {
+		st.ctx = ctx
+		method.Func.Call([]reflect.Value{s.value, reflect.ValueOf(st)})
+	})
+	if failure == "" {
+		if st.canceled != nil && st.canceled.Load() {
+			st.canceler()
+		}
+		return
+	}
+	st.logger(st.prefix, failure)
+	st.canceler()
 }
 
 // exec executes a found Init-method in a Suite.
@@ -61,6 +87,7 @@ func (s *Suite) exec(init *reflect.Method, t *testing.T) {
 		logger:   t.Log,
 		canceler: t.FailNow,
 		prefix:   InitPrefix,
+		expired:  &atomic.Bool{},
 	}
 	if hasLogger {
 		suiteI.logger = suiteLogging.Logger()
@@ -68,8 +95,7 @@ func (s *Suite) exec(init *reflect.Method, t *testing.T) {
 	if hasCanceler {
 		suiteI.canceler = suiteCanceler.Cancel()
 	}
-	init.Func.Call([]reflect.Value{
-		s.value, reflect.ValueOf(suiteI)})
+	s.sWithin(init, suiteI)
 }
 
 // sWrapper wraps given testing.T-instance in a S-instance for a suites
@@ -82,6 +108,7 @@ func (s *Suite) sWrapper(t *testing.T) *S {
 		logger:   t.Log,
 		canceler: t.FailNow,
 		prefix:   FinalPrefix,
+		expired:  &atomic.Bool{},
 	}
 	if hasLogger {
 		suiteT.logger = suiteLogging.Logger()
@@ -98,6 +125,9 @@ func (s *Suite) init(self interface{}, t *testing.T) *Suite {
 	s.self, s.t = self, t
 	s.value = reflect.ValueOf(self)
 	s.rType = reflect.TypeOf(self)
+	if deadliner, ok := self.(SuiteDeadliner); ok {
+		s.deadline = deadliner.Deadline()
+	}
 	for i := 0; i < s.rType.NumMethod(); i++ {
 		m := s.rType.Method(i)
 		switch m.Name {
@@ -108,8 +138,7 @@ func (s *Suite) init(self interface{}, t *testing.T) *Suite {
 		case "Init":
 			s.exec(&m, t)
 		case "Finalize":
-			t.Cleanup(newFinalizer(
-				&m, s.value, reflect.ValueOf(s.sWrapper(t))))
+			t.Cleanup(s.newFinalizer(&m, s.sWrapper(t)))
 		}
 	}
 	return s
@@ -200,6 +229,54 @@ type SuiteCanceler interface {
 	Cancel() func()
 }
 
+// SuiteRetrier implementation of a suite-embedder declares which of its
+// suite-tests are known to be flaky by mapping their method names to
+// the number of retries of a failing test.  Each retry runs the test
+// with a fresh SetUp and TearDown.  The test fails only if all its
+// attempts fail.  The logs of failing attempts are kept prefixed with
+// the attempt number.  A test which passes after a failed attempt is
+// marked as flaky by a [FlakyPrefix]ed log message, e.g.:
+//
+//	type MySuite struct{ gounit.Suite }
+//
+//	func (s *MySuite) Retries() map[string]int {
+//	    return map[string]int{"Integration_test": 2}
+//	}
+//
+//	func (s *MySuite) Integration_test(t *gounit.T) { //... }
+type SuiteRetrier interface {
+	Retries() map[string]int
+}
+
+// SuiteDeadliner implementation of a suite-embedder declares the
+// duration each of its suite-tests (or attempts of a retried test),
+// its Init- and its Finalize-method may take at most.  The context
+// returned by [T.Context] or [S.Context] is cancelled once the
+// deadline expires.  An expired test fails reporting the stacks of its
+// goroutines at that moment while the suite's remaining tests keep
+// running.  An expired Init- or Finalize-method cancels the suite's
+// test-run.  E.g.:
+//
+//	type MySuite struct{ gounit.Suite }
+//
+//	func (s *MySuite) Deadline() time.Duration { return time.Second }
+//
+//	func (s *MySuite) Request_test(t *gounit.T) {
+//	    req, _ := http.NewRequestWithContext(t.Context(), //...
+//	}
+//
+// NOTE a test whose deadline expired is not waited for, i.e. it keeps
+// running concurrently to the suite's next test until it respects its
+// context's cancellation.  Its subsequent logging and error reporting
+// is dropped and its TearDown is skipped.
+type SuiteDeadliner interface {
+	Deadline() time.Duration
+}
+
+// FlakyPrefix prefixes the logging-message of a suite-test which failed
+// at least once before it passed in a retry (see [SuiteRetrier]).
+const FlakyPrefix = testjson.FlakyPrefix
+
 // newSubTestFactory returns for given suite a sub-test-factory, i.e. a
 // function wrapping test-methods into function that can be passed to
 // the Run-method of a *testing.T*-instance.
@@ -209,6 +286,10 @@ func newSubTestFactory(
 	suiteLogging, hasLogger := suite.self.(SuiteLogger)
 	suiteErrorer, hasErrorer := suite.self.(SuiteErrorer)
 	suiteCanceler, hasCanceler := suite.self.(SuiteCanceler)
+	retries := map[string]int{}
+	if suiteRetrier, ok := suite.self.(SuiteRetrier); ok {
+		retries = suiteRetrier.Retries()
+	}
 	var tearDown func(t *T)
 	if suite.tearDown != nil {
 		tearDown = func(t *T) {
@@ -216,42 +297,131 @@ func newSubTestFactory(
 				[]reflect.Value{suite.value, reflect.ValueOf(t)})
 		}
 	}
+	newT := func(t *testing.T, parallel *sync.Once) *T {
+		suiteT := &T{
+			t:        t,
+			tearDown: tearDown,
+			logger:   t.Log,
+			errorer:  t.Error,
+			canceler: t.FailNow,
+			parallel: parallel,
+			expired:  &atomic.Bool{},
+		}
+		suiteT.Not = Not{t: suiteT}
+		if hasLogger {
+			suiteT.logger = suiteLogging.Logger()
+		}
+		if hasErrorer {
+			suiteT.errorer = suiteErrorer.Error()
+		}
+		if hasCanceler {
+			suiteT.canceler = suiteCanceler.Cancel()
+		}
+		return suiteT
+	}
 	return func(test reflect.Method) func(*testing.T) {
 		return func(t *testing.T) {
-			suiteT := &T{
-				t:        t,
-				tearDown: tearDown,
-				logger:   t.Log,
-				errorer:  t.Error,
-				canceler: t.FailNow,
-			}
-			suiteT.Not = Not{t: suiteT}
-			if hasLogger {
-				suiteT.logger = suiteLogging.Logger()
-			}
-			if hasErrorer {
-				suiteT.errorer = suiteErrorer.Error()
+			parallel, n := &sync.Once{}, retries[test.Name]+1
+			for a := 1; a < n; a++ {
+				if !retry(newT(t, parallel), a, n, suite, test) {
+					continue
+				}
+				if a > 1 {
+					t.Log(FlakyPrefix, fmt.Sprintf(
+						"passed at attempt %d of %d", a, n))
+				}
+				return
 			}
-			if hasCanceler {
-				suiteT.canceler = suiteCanceler.Cancel()
+			panicked := attempt(newT(t, parallel), suite, test)
+			if panicked != "" {
+				t.Helper()
+				t.Error(panicked)
 			}
-			suiteTVl := reflect.ValueOf(suiteT)
-			if suite.setUp != nil {
-				(*suite.setUp).Func.Call(
-					[]reflect.Value{suite.value, suiteTVl})
-			}
-			func(vl []reflect.Value) {
-				defer func() {
-					if r := recover(); r != nil {
-						t.Helper()
-						t.Errorf("panicked:\n%v\n%v", r, string(debug.Stack()))
-					}
-				}()
-				test.Func.Call(vl)
-			}([]reflect.Value{suite.value, suiteTVl})
-			if tearDown != nil {
-				tearDown(suiteT)
+			if n > 1 && !t.Failed() {
+				t.Log(FlakyPrefix, fmt.Sprintf(
+					"passed at attempt %d of %d", n, n))
 			}
 		}
 	}
 }
+
+// retry runs given attempt a of n attempts of given suite-test whereas
+// errors and cancellations are only logged prefixed by the attempt
+// number; the later terminates the attempt.  retry returns true iff
+// the attempt passed.
+func retry(
+	suiteT *T, a, n int, suite *Suite, test reflect.Method,
+) (passed bool) {
+	logger, failed := suiteT.logger, false
+	suiteT.logger = func(args ...interface{}) {
+		logger(append([]interface{}{
+			fmt.Sprintf("attempt %d/%d: ", a, n)}, args...)...)
+	}
+	suiteT.errorer = func(args ...interface{}) {
+		failed = true
+		suiteT.logger(args...)
+	}
+	suiteT.canceler = func() {
+		failed = true
+		runtime.Goexit()
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if panicked := attempt(suiteT, suite, test); panicked != "" {
+			suiteT.Error(panicked)
+		}
+	}()
+	<-done
+	return !failed
+}
+
+// attempt runs given suite-test with given T-instance framed by the
+// suite's SetUp and TearDown.  In case the test panics the panic with
+// its stack is returned.  An expired deadline of the suite (see
+// [SuiteDeadliner]) is reported as error.
+func attempt(
+	suiteT *T, suite *Suite, test reflect.Method,
+) (panicked string) {
+	errorer, p := suiteT.errorer, ""
+	if suite.deadline > 0 {
+		suiteT.canceled = &atomic.Bool{}
+	}
+	failure := within(suite.deadline, suiteT.expired,
+		func(ctx context.Context) {
+			suiteT.ctx = ctx
+			p = run(suiteT, suite, test)
+		})
+	if failure != "" {
+		errorer(failure)
+		return ""
+	}
+	if suiteT.canceled != nil && suiteT.canceled.Load() {
+		suiteT.canceler()
+	}
+	return p
+}
+
+// run runs given suite-test with given T-instance framed by the
+// suite's SetUp and TearDown and returns a potential panic with its
+// stack.
+func run(suiteT *T, suite *Suite, test reflect.Method) (panicked string) {
+	suiteTVl := reflect.ValueOf(suiteT)
+	if suite.setUp != nil {
+		(*suite.setUp).Func.Call(
+			[]reflect.Value{suite.value, suiteTVl})
+	}
+	func(vl []reflect.Value) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = fmt.Sprintf(
+					"panicked:\n%v\n%v", r, string(debug.Stack()))
+			}
+		}()
+		test.Func.Call(vl)
+	}([]reflect.Value{suite.value, suiteTVl})
+	if suiteT.tearDown != nil && !suiteT.hasExpired() {
+		suiteT.tearDown(suiteT)
+	}
+	return panicked
+}
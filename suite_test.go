@@ -5,6 +5,7 @@
 package gounit_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -136,6 +137,79 @@ func (s *run) Canceler_implementation_overwrites_cancellation(
 	t.True(len(suite.Got) == 10)
 }
 
+func (s *run) Retries_a_flaky_test_with_fresh_setup_and_tear_down(
+	t *gounit.T,
+) {
+	suite := &fx.TestRetriedFlaky{}
+	if !t.GoT().Run("TestRetriedFlaky", func(_t *testing.T) {
+		gounit.Run(suite, _t)
+	}) {
+		t.GoT().Fatalf("expected TestRetriedFlaky-suite to not fail")
+	}
+	t.Eq("attempt 1/3: sattempt 1/3: 1attempt 1/3: fattempt 1/3: t"+
+		"attempt 2/3: sattempt 2/3: 2attempt 2/3: t", suite.Logs)
+}
+
+func (s *run) Fails_a_retried_test_failing_in_all_attempts(t *gounit.T) {
+	suite := &fx.TestRetriedFailing{}
+	gounit.Run(suite, t.GoT())
+	t.Eq("attempt 1/2: eE", suite.Logs)
+}
+
+func (s *run) Fails_a_test_exceeding_the_suite_s_deadline(t *gounit.T) {
+	suite := &fx.TestDeadline{
+		Release: make(chan struct{}), Done: make(chan struct{})}
+	gounit.Run(suite, t.GoT())
+	t.FatalIfNot(t.True(suite.Ctx != nil))
+	t.ErrIs(suite.Ctx.Err(), context.Canceled)
+	t.Contains(suite.Logs, "gounit: deadline of 20ms exceeded")
+	t.Contains(suite.Logs, "fx.(*TestDeadline).Hanging_test")
+	t.Not.Contains(suite.Logs, "testing.(*T).Run(")
+	close(suite.Release)
+	<-suite.Done
+	t.Not.Contains(suite.Logs, "late")
+}
+
+func (s *run) Cancels_a_test_with_deadline_on_the_test_s_goroutine(
+	t *gounit.T,
+) {
+	suite := &fx.TestDeadlineFatal{}
+	gounit.Run(suite, t.GoT())
+	t.Eq("fC", suite.Logs)
+}
+
+func (s *run) Reports_stacks_of_a_test_returning_after_its_deadline(
+	t *gounit.T,
+) {
+	suite := &fx.TestDeadlineReturn{}
+	gounit.Run(suite, t.GoT())
+	t.Contains(suite.Logs,
+		"gounit: deadline of 10ms exceeded; goroutines:\ngoroutine ")
+}
+
+func (s *run) Cancels_suite_if_init_exceeds_the_suite_s_deadline(
+	t *gounit.T,
+) {
+	suite := &fx.TestInitDeadline{}
+	gounit.Run(suite, t.GoT())
+	t.Contains(suite.Logs,
+		gounit.InitPrefix+"gounit: deadline of 10ms exceeded")
+	t.True(strings.HasSuffix(suite.Logs, "C"))
+}
+
+func (s *run) Provides_a_context_cancelled_at_the_end_of_a_test(
+	t *gounit.T,
+) {
+	var ctx context.Context
+	t.GoT().Run("ctx", func(_t *testing.T) {
+		ctx = gounit.NewT(_t).Context()
+		if ctx.Err() != nil {
+			_t.Error("expected context not to be cancelled")
+		}
+	})
+	t.ErrIs(ctx.Err(), context.Canceled)
+}
+
 func TestRun(t *testing.T) {
 	t.Parallel()
 	gounit.Run(&run{}, t)
@@ -5,10 +5,15 @@
 package gounit
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/slukits/gounit/pkg/testjson"
 	"github.com/slukits/gounit/pkg/tfs"
 )
 
@@ -58,6 +63,24 @@ type T struct {
 	canceler func()
 	fs       *tfs.FS
 
+	// parallel is shared by the attempts of a retried suite-test (see
+	// [SuiteRetrier]) to call Parallel only once on wrapped testing.T.
+	parallel *sync.Once
+
+	// ctx is the context of a suite-test (see [T.Context]).
+	ctx context.Context
+
+	// expired is set once the deadline of a suite-test (see
+	// [SuiteDeadliner]) expired; later logging and error reporting is
+	// dropped while a cancellation terminates the test's goroutine.
+	expired *atomic.Bool
+
+	// canceled is set iff the suite-test runs in a worker goroutine
+	// because of a deadline; a cancellation is recorded in it and
+	// terminates the worker while the test's goroutine calls the
+	// canceler (see [within]).
+	canceled *atomic.Bool
+
 	// Not provides negations of T-assertions like Contains or StarMatched.
 	Not Not
 }
@@ -91,6 +114,9 @@ func (t T) GoT() *testing.T { return t.t }
 // leveraging [T.Mock].
 func (t T) Log(args ...interface{}) {
 	t.t.Helper()
+	if t.hasExpired() {
+		return
+	}
 	t.logger(args...)
 }
 
@@ -104,7 +130,16 @@ func (t T) Logf(format string, args ...interface{}) {
 
 // Parallel signals that this test may be run in parallel with other
 // parallel flagged tests.
-func (t T) Parallel() { t.t.Parallel() }
+func (t T) Parallel() {
+	if t.hasExpired() {
+		return
+	}
+	if t.parallel == nil {
+		t.t.Parallel()
+		return
+	}
+	t.parallel.Do(t.t.Parallel)
+}
 
 // Error logs given arguments and flags test as failed but continues its
 // execution.  t's errorer defaults to a Error-call of a wrapped
@@ -112,6 +147,9 @@ func (t T) Parallel() { t.t.Parallel() }
 // implementing [SuiteErrorer] or leveraging [T.Mock].
 func (t T) Error(args ...interface{}) {
 	t.t.Helper()
+	if t.hasExpired() {
+		return
+	}
 	t.errorer(args...)
 }
 
@@ -130,12 +168,24 @@ func (t T) Errorf(format string, args ...interface{}) {
 // implementing [SuiteCanceler] or leveraging [T.Mock].
 func (t *T) FailNow() {
 	t.t.Helper()
+	if t.hasExpired() {
+		runtime.Goexit()
+	}
 	if t.tearDown != nil {
 		t.tearDown(t)
 	}
+	if t.canceled != nil {
+		t.canceled.Store(true)
+		runtime.Goexit()
+	}
 	t.canceler()
 }
 
+// hasExpired returns true iff t's suite-test deadline expired.
+func (t T) hasExpired() bool {
+	return t.expired != nil && t.expired.Load()
+}
+
 // FatalIfNot cancels the test execution (see [T.FailNow]) if passed
 // argument is false and is a no-op otherwise.
 func (t T) FatalIfNot(assertion bool) {
@@ -205,11 +255,11 @@ func (t *T) FS() *tfs.FS {
 
 // InitPrefix prefixes logging-messages of the Init-method to enable the
 // reporter to discriminate Init-logs and Finalize-logs.
-const InitPrefix = "__init__"
+const InitPrefix = testjson.InitPrefix
 
 // FinalPrefix prefixes logging-messages of the Finalize-method to
 // enable the reporter to discriminate Finalize-logs and Init-logs.
-const FinalPrefix = "__final__"
+const FinalPrefix = testjson.FinalPrefix
 
 // S instances are passed from gounit into a test-suite's Init or
 // Finalize method, i.e. it is the "T"-instance of an Init/Finalize
@@ -238,6 +288,9 @@ type S struct {
 	canceler func()
 	prefix   string
 	fs       *tfs.FS
+	ctx      context.Context
+	expired  *atomic.Bool
+	canceled *atomic.Bool
 }
 
 // GoT returns a pointer to wrapped testing.T instance of the
@@ -248,6 +301,9 @@ func (st S) GoT() *testing.T { return st.t }
 // is superseded by an optional [SuiteLogging]-implementation.
 func (st S) Log(args ...interface{}) {
 	st.t.Helper()
+	if st.hasExpired() {
+		return
+	}
 	st.logger(append([]interface{}{st.prefix}, args...)...)
 }
 
@@ -259,6 +315,27 @@ func (st S) Logf(format string, args ...interface{}) {
 	st.Log(fmt.Sprintf(format, args...))
 }
 
+// hasExpired returns true iff the deadline of st's Init- or
+// Finalize-method expired.
+func (st S) hasExpired() bool {
+	return st.expired != nil && st.expired.Load()
+}
+
+// cancel calls st's canceler unless its deadline expired or it runs in
+// a worker goroutine in which case the calling goroutine is terminated;
+// the later records the cancellation for the suite runner's goroutine
+// (see [within]).
+func (st S) cancel() {
+	if st.hasExpired() {
+		runtime.Goexit()
+	}
+	if st.canceled != nil {
+		st.canceled.Store(true)
+		runtime.Goexit()
+	}
+	st.canceler()
+}
+
 // Fatal cancels the test-suite's test-run after given arguments were
 // logged.  The cancellation defaults to a FailNow call of wrapped
 // test-runner's testing.T-instance which is superseded by an optional
@@ -266,7 +343,7 @@ func (st S) Logf(format string, args ...interface{}) {
 func (st S) Fatal(args ...interface{}) {
 	st.t.Helper()
 	st.Log(args...)
-	st.canceler()
+	st.cancel()
 }
 
 // Fatalf cancels the test-suite's test-run after given arguments were
@@ -276,7 +353,7 @@ func (st S) Fatal(args ...interface{}) {
 func (st S) Fatalf(format string, args ...interface{}) {
 	st.t.Helper()
 	st.Logf(format, args...)
-	st.canceler()
+	st.cancel()
 }
 
 // FatalOn cancels the test-suite's test-run iff given error is not
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	. "github.com/slukits/gounit"
+	"github.com/slukits/gounit/pkg/tfs"
 	"github.com/slukits/gounit/testdata/fx"
 )
 
@@ -361,6 +362,35 @@ func (s *AssertionTests) For_within(t *T) {
 	}
 }
 
+func (s *AssertionTests) For_directory_equality(t *T) {
+	fxDirs := func(t *T) (*tfs.Dir, *tfs.Dir) {
+		a, b := t.FS().Tmp(), t.FS().Tmp()
+		a.MkFile("a.txt", []byte("a\r\n"))
+		b.MkFile("a.txt", []byte("a\n"))
+		return a, b
+	}
+	suite := &fx.TestAssertion{
+		True: func(t *T) bool {
+			a, b := fxDirs(t)
+			return t.DirEq(a, b, tfs.IgnoreLineEndings())
+		},
+		False: func(t *T) bool {
+			a, b := fxDirs(t)
+			return t.DirEq(a, b)
+		},
+		Fails: func(t *T) string {
+			a, b := fxDirs(t)
+			t.DirEq(a, b)
+			return "directories differ:\ncontent:\n    a.txt:"
+		},
+	}
+	if !t.GoT().Run("AssertDirEq", func(_t *testing.T) {
+		Run(suite, _t)
+	}) {
+		t.GoT().Fatalf("assertion suite failed: %s", suite.Msg)
+	}
+}
+
 func TestAssertionTests(t *testing.T) {
 	Run(&AssertionTests{}, t)
 }
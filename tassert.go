@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/slukits/gounit/pkg/tfs"
 )
 
 // trueErr default message for failed 'true'-assertion.
@@ -96,6 +97,22 @@ func (t T) Eq(a, b interface{}) bool {
 	return true
 }
 
+// dirEqErr default message for failed 'dir-equal'-assertion.
+const dirEqErr = "directories differ:\n%s"
+
+// DirEq fails the test reporting the differences and returns false iff
+// given directory d differs from given other directory as reported by
+// [tfs.Dir.Diff] using given options; otherwise true is returned.
+func (t T) DirEq(d *tfs.Dir, other tfs.Pather, oo ...tfs.DiffOption) bool {
+	t.t.Helper()
+	diff := d.Diff(other, oo...)
+	if diff.Empty() {
+		return true
+	}
+	t.Errorf(assertErr, "dir-equal", fmt.Sprintf(dirEqErr, diff))
+	return false
+}
+
 func isStringers(a, b interface{}) bool {
 	_, okA := a.(fmt.Stringer)
 	_, okB := b.(fmt.Stringer)
@@ -11,6 +11,7 @@
 package fx
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"runtime"
@@ -431,3 +432,161 @@ func (s *TestInitFinalHaveRunTest) Finalize(t *gounit.S) {
 	}
 	t.Log(s.FinalLog)
 }
+
+// TestRetriedFlaky has its Flaky_test fail in the first attempt and
+// pass in the second attempt whereas SetUp logs "s", TearDown "t" and
+// the test logs the attempt number.  I.e. the logs of each attempt are
+// prefixed by the attempt number and contain "s" and "t" iff the test
+// is retried with a fresh setup and tear down.
+type TestRetriedFlaky struct {
+	FixtureLog
+	gounit.Suite
+	attempts int
+}
+
+func (s *TestRetriedFlaky) Retries() map[string]int {
+	return map[string]int{"Flaky_test": 2}
+}
+
+func (s *TestRetriedFlaky) SetUp(t *gounit.T) { t.Log("s") }
+
+func (s *TestRetriedFlaky) TearDown(t *gounit.T) { t.Log("t") }
+
+func (s *TestRetriedFlaky) Flaky_test(t *gounit.T) {
+	s.attempts++
+	t.Log(s.attempts)
+	if s.attempts == 1 {
+		t.Fatal("f")
+	}
+}
+
+func (s *TestRetriedFlaky) File() string { return file }
+
+// TestRetriedFailing has its Failing_test fail in each attempt while
+// its errors are logged as "E" on the final attempt.  I.e. the suite
+// logs "attempt 1/2: eE" iff a test failing in all attempts fails in
+// its final attempt.
+type TestRetriedFailing struct {
+	FixtureLog
+	gounit.Suite
+}
+
+func (s *TestRetriedFailing) Retries() map[string]int {
+	return map[string]int{"Failing_test": 1}
+}
+
+func (s *TestRetriedFailing) Error() func(...interface{}) {
+	return func(...interface{}) { s.log("E") }
+}
+
+func (s *TestRetriedFailing) Failing_test(t *gounit.T) { t.Error("e") }
+
+func (s *TestRetriedFailing) File() string { return file }
+
+// TestDeadline has a deadline of 20ms which expires while its
+// Hanging_test blocks until Release is closed.  Errors are logged, the
+// context of Fast_test is kept in Ctx and Hanging_test closes Done
+// after its (dropped) logging attempt "late".
+type TestDeadline struct {
+	FixtureLog
+	gounit.Suite
+	Release, Done chan struct{}
+	Ctx           context.Context
+}
+
+func (s *TestDeadline) Deadline() time.Duration {
+	return 20 * time.Millisecond
+}
+
+func (s *TestDeadline) Error() func(...interface{}) { return s.log }
+
+func (s *TestDeadline) Fast_test(t *gounit.T) { s.Ctx = t.Context() }
+
+func (s *TestDeadline) Hanging_test(t *gounit.T) {
+	defer close(s.Done)
+	<-t.Context().Done()
+	<-s.Release
+	t.Log("late")
+}
+
+func (s *TestDeadline) File() string { return file }
+
+// TestInitDeadline has an Init-method which blocks until its context is
+// cancelled due to the suite's deadline of 10ms.  The resulting
+// cancellation is logged as "C".
+type TestInitDeadline struct {
+	FixtureLog
+	gounit.Suite
+}
+
+func (s *TestInitDeadline) Deadline() time.Duration {
+	return 10 * time.Millisecond
+}
+
+func (s *TestInitDeadline) Init(t *gounit.S) { <-t.Context().Done() }
+
+func (s *TestInitDeadline) Cancel() func() {
+	return func() { s.log("C") }
+}
+
+func (s *TestInitDeadline) A_test(t *gounit.T) {}
+
+func (s *TestInitDeadline) File() string { return file }
+
+// TestDeadlineFatal has a deadline of 1s which doesn't expire while its
+// Fatal_test fails fatally.  The cancellation is logged as "C" iff it
+// happens on another goroutine than the one running Fatal_test.
+type TestDeadlineFatal struct {
+	FixtureLog
+	gounit.Suite
+	worker string
+}
+
+func (s *TestDeadlineFatal) Deadline() time.Duration { return time.Second }
+
+func (s *TestDeadlineFatal) Cancel() func() {
+	return func() {
+		if goroutine() != s.worker {
+			s.log("C")
+		}
+	}
+}
+
+func (s *TestDeadlineFatal) Fatal_test(t *gounit.T) {
+	s.worker = goroutine()
+	t.Fatal("f")
+	s.log("after")
+}
+
+func (s *TestDeadlineFatal) File() string { return file }
+
+// TestDeadlineReturn has a deadline of 10ms and its Returning_test
+// returns as soon as its context is cancelled.  Errors are logged.
+type TestDeadlineReturn struct {
+	FixtureLog
+	gounit.Suite
+}
+
+func (s *TestDeadlineReturn) Deadline() time.Duration {
+	return 10 * time.Millisecond
+}
+
+func (s *TestDeadlineReturn) Error() func(...interface{}) { return s.log }
+
+func (s *TestDeadlineReturn) Returning_test(t *gounit.T) {
+	<-t.Context().Done()
+}
+
+func (s *TestDeadlineReturn) File() string { return file }
+
+// goroutine returns the header of the calling goroutine's stack trace.
+func goroutine() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	for i, b := range buf {
+		if b == '[' {
+			return string(buf[:i])
+		}
+	}
+	return string(buf)
+}